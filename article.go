@@ -0,0 +1,171 @@
+package summergo
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// サイトによって書き方がばらばらなので順に試す
+var articleTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// parseArticleTime parses a date in the formats used by article:published_time and schema.org.
+// Times without a zone are taken as UTC.
+func parseArticleTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	for _, layout := range articleTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// 最初に解析できた日時を返す
+func firstArticleTime(values ...string) time.Time {
+	for _, value := range values {
+		if t, ok := parseArticleTime(value); ok {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func isAbsoluteUrl(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.IsAbs() && u.Host != ""
+}
+
+// 構造化データのauthorの名前
+// PersonやOrganizationの場合と文字列の場合がある
+func structuredDataAuthors(items []*StructuredDataItem, main *StructuredDataItem) []string {
+	var authors []string
+	for _, name := range []string{"author", "creator"} {
+		for _, value := range main.Properties[name] {
+			var author string
+			switch v := value.(type) {
+			case string:
+				author = v
+			case *StructuredDataItem:
+				author = dereferenceStructuredData(items, v).String("name")
+			}
+
+			if author = strings.TrimSpace(author); author != "" && !isAbsoluteUrl(author) && !slices.Contains(authors, author) {
+				authors = append(authors, author)
+			}
+		}
+		if len(authors) > 0 {
+			break
+		}
+	}
+	return authors
+}
+
+// 記事の著者
+// article:authorはプロフィールのURLであることが多いので名前が得られるものを優先する
+func getArticleAuthors(idx *metaIndex, items []*StructuredDataItem, main *StructuredDataItem) []string {
+	if author := idx.find(&findParam{tagName: "meta", attrKey: "name", attrValue: "author", targetKey: "content"}); author != "" {
+		return []string{author}
+	}
+
+	var authors []string
+	for _, author := range idx.findAll(&findParam{tagName: "meta", attrKey: "property", attrValue: "article:author", targetKey: "content"}) {
+		if !isAbsoluteUrl(author) && !slices.Contains(authors, author) {
+			authors = append(authors, author)
+		}
+	}
+	if len(authors) > 0 {
+		return authors
+	}
+
+	if main != nil {
+		if authors := structuredDataAuthors(items, main); len(authors) > 0 {
+			return authors
+		}
+	}
+
+	if creator := idx.find([]*findParam{
+		{tagName: "meta", attrKey: "name", attrValue: "twitter:creator", targetKey: "content"},
+		{tagName: "meta", attrKey: "property", attrValue: "twitter:creator", targetKey: "content"},
+	}...); creator != "" {
+		return []string{creator}
+	}
+
+	return nil
+}
+
+// article:tagがなければ構造化データのkeywordsを使う
+// keywordsはカンマ区切りの文字列のこともある
+func getArticleTags(idx *metaIndex, main *StructuredDataItem) []string {
+	tags := idx.findAll(&findParam{tagName: "meta", attrKey: "property", attrValue: "article:tag", targetKey: "content"})
+	if len(tags) > 0 || main == nil {
+		return tags
+	}
+
+	for _, value := range main.Properties["keywords"] {
+		keywords, ok := value.(string)
+		if !ok {
+			continue
+		}
+		for _, keyword := range strings.Split(keywords, ",") {
+			if keyword = strings.TrimSpace(keyword); keyword != "" && !slices.Contains(tags, keyword) {
+				tags = append(tags, keyword)
+			}
+		}
+	}
+	return tags
+}
+
+// getArticle collects the article metadata from Open Graph, author meta tags, twitter:creator
+// and structured data. It returns nil when the page has none of them.
+func getArticle(idx *metaIndex, items []*StructuredDataItem) *Article {
+	main := mainStructuredDataItem(items)
+	structured := func(name string) string {
+		if main == nil {
+			return ""
+		}
+		return main.String(name)
+	}
+
+	article := &Article{
+		Authors: getArticleAuthors(idx, items, main),
+		Published: firstArticleTime(
+			idx.find(&findParam{tagName: "meta", attrKey: "property", attrValue: "article:published_time", targetKey: "content"}),
+			structured("datePublished"),
+			structured("dateCreated"),
+		),
+		Modified: firstArticleTime(
+			idx.find([]*findParam{
+				{tagName: "meta", attrKey: "property", attrValue: "article:modified_time", targetKey: "content"},
+				{tagName: "meta", attrKey: "property", attrValue: "og:updated_time", targetKey: "content"},
+			}...),
+			structured("dateModified"),
+		),
+		Section: idx.find(&findParam{tagName: "meta", attrKey: "property", attrValue: "article:section", targetKey: "content"}),
+		Tags:    getArticleTags(idx, main),
+	}
+	if article.Section == "" {
+		article.Section = structured("articleSection")
+	}
+
+	if len(article.Authors) == 0 && article.Published.IsZero() && article.Modified.IsZero() && article.Section == "" && len(article.Tags) == 0 {
+		return nil
+	}
+	return article
+}
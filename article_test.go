@@ -0,0 +1,148 @@
+package summergo
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseArticleTime(t *testing.T) {
+	jst := time.FixedZone("", 9*60*60)
+	tests := map[string]time.Time{
+		"2024-01-02T03:04:05Z":          time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		"2024-01-02T03:04:05.5+09:00":   time.Date(2024, 1, 2, 3, 4, 5, 500000000, jst),
+		"2024-01-02T03:04+09:00":        time.Date(2024, 1, 2, 3, 4, 0, 0, jst),
+		"2024-01-02T03:04:05+0900":      time.Date(2024, 1, 2, 3, 4, 5, 0, jst),
+		"2024-01-02T03:04:05":           time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		"2024-01-02 03:04:05":           time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		" 2024-01-02 ":                  time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		"Tue, 02 Jan 2024 03:04:05 GMT": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	for value, expected := range tests {
+		result, ok := parseArticleTime(value)
+		if !ok || !result.Equal(expected) {
+			t.Errorf("%q: Expected: %v, Got: %v", value, expected, result)
+		}
+	}
+
+	for _, value := range []string{"", "yesterday", "2024/01/02"} {
+		if _, ok := parseArticleTime(value); ok {
+			t.Errorf("%q should be invalid", value)
+		}
+	}
+}
+
+func TestSummarizeHtmlArticle(t *testing.T) {
+	siteUrl, _ := url.Parse("https://example.com/news/1")
+	s := NewSummarizer(WithOEmbed(false), WithManifest(false))
+
+	page := `<html><head>
+		<title>News</title>
+		<meta name="description" content="Description">
+		<meta property="article:published_time" content="2024-01-02T03:04:05+09:00">
+		<meta property="article:author" content="https://www.facebook.com/example">
+		<meta property="article:section" content="Technology">
+		<meta property="article:tag" content="Go">
+		<meta property="article:tag" content="HTML">
+		<meta name="twitter:creator" content="@example">
+		<script type="application/ld+json">{
+			"@type": "NewsArticle",
+			"dateModified": "2024-01-03T00:00:00Z",
+			"author": [{"@type": "Person", "name": "Alice"}, {"@type": "Person", "name": "Bob"}]
+		}</script>
+	</head><body></body></html>`
+
+	summary, err := s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	article := summary.Article
+	if article == nil {
+		t.Fatal("article should be set")
+	}
+
+	// プロフィールのURLより構造化データの名前を優先する
+	if strings.Join(article.Authors, ",") != "Alice,Bob" {
+		t.Errorf("Expected: %s, Got: %v", "Alice,Bob", article.Authors)
+	}
+	if expected := time.Date(2024, 1, 1, 18, 4, 5, 0, time.UTC); !article.Published.Equal(expected) {
+		t.Errorf("Expected: %v, Got: %v", expected, article.Published)
+	}
+	if expected := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC); !article.Modified.Equal(expected) {
+		t.Errorf("Expected: %v, Got: %v", expected, article.Modified)
+	}
+	if article.Section != "Technology" {
+		t.Errorf("Expected: %s, Got: %s", "Technology", article.Section)
+	}
+	if strings.Join(article.Tags, ",") != "Go,HTML" {
+		t.Errorf("Expected: %s, Got: %v", "Go,HTML", article.Tags)
+	}
+
+	// metaタグのauthorがあればそれを使う
+	page = `<title>News</title><meta name="author" content="Carol"><meta name="twitter:creator" content="@example">`
+	summary, err = s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Article == nil || strings.Join(summary.Article.Authors, ",") != "Carol" {
+		t.Errorf("unexpected article: %+v", summary.Article)
+	}
+
+	// twitter:creatorとkeywordsしかない場合
+	page = `<title>News</title><meta name="twitter:creator" content="@example"><script type="application/ld+json">{"@type": "BlogPosting", "keywords": "go, html , go"}</script>`
+	summary, err = s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Article == nil || strings.Join(summary.Article.Authors, ",") != "@example" || strings.Join(summary.Article.Tags, ",") != "go,html" {
+		t.Errorf("unexpected article: %+v", summary.Article)
+	}
+
+	// 記事の情報がなければnil
+	summary, err = s.SummarizeHtml(*siteUrl, strings.NewReader(`<title>Page</title>`), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Article != nil {
+		t.Errorf("Expected nil, Got: %+v", summary.Article)
+	}
+}
+
+func TestSummarizeHtmlArticleInBody(t *testing.T) {
+	siteUrl, _ := url.Parse("https://example.com/news/1")
+	s := NewSummarizer(WithOEmbed(false), WithManifest(false))
+
+	body := `<body><article>
+		<meta property="article:published_time" content="2024-01-02T03:04:05Z">
+		<script type="application/ld+json">{"@type": "NewsArticle", "author": {"@type": "Person", "name": "Alice"}, "dateModified": "2024-01-03"}</script>
+		<p>text</p>
+	</article></body>`
+
+	// headが揃っていても本文の記事の情報を使う
+	for _, head := range []string{
+		`<title>News</title><meta name="description" content="Description"><meta property="og:image" content="/og.jpg">`,
+		`<title>News</title><meta name="description" content="Description">`,
+	} {
+		summary, err := s.SummarizeHtml(*siteUrl, strings.NewReader(`<html><head>`+head+`</head>`+body+`</html>`), "utf-8")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		article := summary.Article
+		if article == nil {
+			t.Fatal("article should be set")
+		}
+		if expected := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC); !article.Published.Equal(expected) {
+			t.Errorf("Expected: %v, Got: %v", expected, article.Published)
+		}
+		if expected := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC); !article.Modified.Equal(expected) {
+			t.Errorf("Expected: %v, Got: %v", expected, article.Modified)
+		}
+		if strings.Join(article.Authors, ",") != "Alice" {
+			t.Errorf("Expected: %s, Got: %v", "Alice", article.Authors)
+		}
+	}
+}
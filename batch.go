@@ -0,0 +1,160 @@
+package summergo
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultBatchConcurrency        = 8
+	defaultBatchPerHostConcurrency = 2
+)
+
+// BatchOptions configures SummarizeMany.
+type BatchOptions struct {
+	// Concurrency is the maximum number of URLs summarized at once. Defaults to 8.
+	Concurrency int
+	// PerHostConcurrency is the maximum number of URLs of a single host summarized at once. Defaults to 2.
+	PerHostConcurrency int
+}
+
+// BatchResult is the result of summarizing one URL with SummarizeMany.
+type BatchResult struct {
+	Url     string
+	Summary *Summary
+	Err     error
+}
+
+// SummarizeMany summarizes urls using the default Summarizer.
+func SummarizeMany(ctx context.Context, urls []string, opts BatchOptions) []BatchResult {
+	return defaultSummarizer.SummarizeMany(ctx, urls, opts)
+}
+
+// 同じホストのURLを同時に取得しすぎないようにURLを渡す
+// ホストごとに待ち行列を持ち、上限に達していないホストのURLだけを順番に渡すので、
+// ワーカーが始められないURLを抱えて待つことはない
+type batchScheduler struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	perHost int
+	pending int
+	queues  map[string][]int
+	active  map[string]int
+	// 待っているURLがあり、上限に達していないホスト
+	ready []string
+}
+
+func newBatchScheduler(urls []string, perHost int) *batchScheduler {
+	bs := &batchScheduler{
+		perHost: perHost,
+		pending: len(urls),
+		queues:  make(map[string][]int),
+		active:  make(map[string]int),
+	}
+	bs.cond = sync.NewCond(&bs.mu)
+
+	for i, siteUrl := range urls {
+		// 不正なURLはすぐに失敗するのでまとめて扱う
+		var host string
+		if parsedUrl, err := url.Parse(siteUrl); err == nil {
+			host = strings.ToLower(parsedUrl.Hostname())
+		}
+
+		if _, ok := bs.queues[host]; !ok {
+			bs.ready = append(bs.ready, host)
+		}
+		bs.queues[host] = append(bs.queues[host], i)
+	}
+
+	return bs
+}
+
+// 次に取得するURLの番号を返す
+// 残りがなければfalseを返す
+func (bs *batchScheduler) next() (int, string, bool) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	for len(bs.ready) == 0 {
+		if bs.pending == 0 {
+			return 0, "", false
+		}
+		bs.cond.Wait()
+	}
+
+	host := bs.ready[0]
+	bs.ready = bs.ready[1:]
+
+	i := bs.queues[host][0]
+	bs.queues[host] = bs.queues[host][1:]
+	bs.active[host]++
+	bs.pending--
+
+	// 他のホストと交互に渡す
+	if len(bs.queues[host]) > 0 && bs.active[host] < bs.perHost {
+		bs.ready = append(bs.ready, host)
+	}
+
+	return i, host, true
+}
+
+func (bs *batchScheduler) done(host string) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	bs.active[host]--
+	// 上限に達していたホストを戻す
+	if len(bs.queues[host]) > 0 && bs.active[host] == bs.perHost-1 {
+		bs.ready = append(bs.ready, host)
+	}
+	bs.cond.Broadcast()
+}
+
+// SummarizeMany summarizes urls concurrently and returns the results in the same order as urls.
+// URLs are handed to a fixed number of workers in order, skipping hosts that already have
+// PerHostConcurrency URLs in progress.
+func (s *Summarizer) SummarizeMany(ctx context.Context, urls []string, opts BatchOptions) []BatchResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	perHostConcurrency := opts.PerHostConcurrency
+	if perHostConcurrency <= 0 {
+		perHostConcurrency = defaultBatchPerHostConcurrency
+	}
+
+	results := make([]BatchResult, len(urls))
+	for i, siteUrl := range urls {
+		results[i].Url = siteUrl
+	}
+
+	scheduler := newBatchScheduler(urls, perHostConcurrency)
+
+	// URLの数によらずゴルーチンは同時実行数だけ起動する
+	var wg sync.WaitGroup
+	for range min(concurrency, len(urls)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i, host, ok := scheduler.next()
+				if !ok {
+					return
+				}
+
+				// キャンセルされたら残りは取得しない
+				if err := ctx.Err(); err != nil {
+					results[i].Err = wrapRequestError(err)
+				} else {
+					results[i].Summary, results[i].Err = s.SummarizeContext(ctx, urls[i])
+				}
+				scheduler.done(host)
+			}
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
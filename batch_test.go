@@ -0,0 +1,188 @@
+package summergo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSummarizeMany(t *testing.T) {
+	var mu sync.Mutex
+	active := 0
+	maxActive := 0
+	activeByHost := make(map[string]int)
+	maxActiveByHost := 0
+
+	s := NewSummarizer(
+		WithOEmbed(false),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			active++
+			activeByHost[req.URL.Host]++
+			maxActive = max(maxActive, active)
+			maxActiveByHost = max(maxActiveByHost, activeByHost[req.URL.Host])
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			active--
+			activeByHost[req.URL.Host]--
+			mu.Unlock()
+
+			if req.URL.Path == "/404" {
+				return newTestResponse(req, 404, "text/html", ""), nil
+			}
+			return newTestResponse(req, 200, "text/html; charset=utf-8", testPageHtml), nil
+		})),
+	)
+
+	urls := []string{"https://example.com/404", "://invalid"}
+	for _, host := range []string{"a.example.com", "b.example.com", "c.example.com"} {
+		for _, path := range []string{"/1", "/2", "/3", "/4", "/5"} {
+			urls = append(urls, "https://"+host+path)
+		}
+	}
+
+	results := s.SummarizeMany(context.Background(), urls, BatchOptions{Concurrency: 4, PerHostConcurrency: 2})
+	if len(results) != len(urls) {
+		t.Fatalf("Expected: %d, Got: %d", len(urls), len(results))
+	}
+
+	for i, result := range results {
+		if result.Url != urls[i] {
+			t.Errorf("Expected: %s, Got: %s", urls[i], result.Url)
+		}
+	}
+
+	var statusErr *HTTPStatusError
+	if !errors.As(results[0].Err, &statusErr) {
+		t.Errorf("Expected: *HTTPStatusError, Got: %v", results[0].Err)
+	}
+	if !errors.Is(results[1].Err, ErrInvalidURL) {
+		t.Errorf("Expected: %v, Got: %v", ErrInvalidURL, results[1].Err)
+	}
+	for _, result := range results[2:] {
+		if result.Err != nil || result.Summary.Title != "Test Page" {
+			t.Errorf("unexpected result: %+v", result)
+		}
+	}
+
+	if maxActive > 4 {
+		t.Errorf("concurrency should be limited to %d: %d", 4, maxActive)
+	}
+	if maxActiveByHost > 2 {
+		t.Errorf("per-host concurrency should be limited to %d: %d", 2, maxActiveByHost)
+	}
+}
+
+func TestSummarizeManyCanceled(t *testing.T) {
+	s := NewSummarizer(WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	results := s.SummarizeMany(ctx, []string{"https://example.com/1", "https://example.com/2", "https://example.com/3"}, BatchOptions{PerHostConcurrency: 1})
+	for _, result := range results {
+		if !errors.Is(result.Err, ErrTimeout) {
+			t.Errorf("Expected: %v, Got: %v", ErrTimeout, result.Err)
+		}
+	}
+}
+
+func TestSummarizeManyWorkers(t *testing.T) {
+	s := NewSummarizer(
+		WithOEmbed(false),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return newTestResponse(req, 200, "text/html; charset=utf-8", testPageHtml), nil
+		})),
+	)
+
+	var urls []string
+	for i := range 200 {
+		urls = append(urls, fmt.Sprintf("https://host%d.example.com/", i))
+	}
+
+	// URLの数だけゴルーチンを起動しない
+	before := runtime.NumGoroutine()
+	done := make(chan []BatchResult)
+	go func() {
+		done <- s.SummarizeMany(context.Background(), urls, BatchOptions{Concurrency: 4})
+	}()
+
+	peak := 0
+	for {
+		select {
+		case results := <-done:
+			for _, result := range results {
+				if result.Err != nil {
+					t.Errorf("unexpected error: %v", result.Err)
+				}
+			}
+			// 呼び出し、ワーカー、取得処理などの分だけ増える
+			if peak-before > 4*4+2 {
+				t.Errorf("too many goroutines: %d", peak-before)
+			}
+			return
+		default:
+			peak = max(peak, runtime.NumGoroutine())
+			runtime.Gosched()
+		}
+	}
+}
+
+func TestSummarizeManyClusteredHosts(t *testing.T) {
+	var mu sync.Mutex
+	activeOthers := 0
+	maxActiveOthers := 0
+
+	s := NewSummarizer(
+		WithOEmbed(false),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			other := req.URL.Host != "a.example.com"
+			if other {
+				mu.Lock()
+				activeOthers++
+				maxActiveOthers = max(maxActiveOthers, activeOthers)
+				mu.Unlock()
+			}
+
+			time.Sleep(50 * time.Millisecond)
+
+			if other {
+				mu.Lock()
+				activeOthers--
+				mu.Unlock()
+			}
+			return newTestResponse(req, 200, "text/html; charset=utf-8", testPageHtml), nil
+		})),
+	)
+
+	// 同じホストのURLが先頭に固まっていても他のホストのURLを待たせない
+	var urls []string
+	for i := range 8 {
+		urls = append(urls, fmt.Sprintf("https://a.example.com/%d", i))
+	}
+	for i := range 8 {
+		urls = append(urls, fmt.Sprintf("https://host%d.example.com/", i))
+	}
+
+	results := s.SummarizeMany(context.Background(), urls, BatchOptions{Concurrency: 8, PerHostConcurrency: 2})
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("unexpected error: %v", result.Err)
+		}
+	}
+
+	if maxActiveOthers < 6 {
+		t.Errorf("other hosts should use the remaining %d workers: %d", 6, maxActiveOthers)
+	}
+}
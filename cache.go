@@ -0,0 +1,251 @@
+package summergo
+
+import (
+	"container/list"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheMinTTL = 10 * time.Minute
+	defaultCacheMaxTTL = 24 * time.Hour
+)
+
+// Cache stores summaries keyed on the normalized URL and language.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the summary stored for key unless it has expired.
+	Get(key string) (*Summary, bool)
+	// Set stores summary for key until expiresAt.
+	Set(key string, summary *Summary, expiresAt time.Time)
+}
+
+// キャッシュのキーに使うURLを正規化する
+func normalizeUrl(u *url.URL) string {
+	normalized := *u
+	normalized.Scheme = strings.ToLower(normalized.Scheme)
+	normalized.Host = strings.ToLower(normalized.Host)
+	normalized.Fragment = ""
+	normalized.RawFragment = ""
+
+	// デフォルトのポートは省略する
+	if port := normalized.Port(); (normalized.Scheme == "https" && port == "443") || (normalized.Scheme == "http" && port == "80") {
+		normalized.Host = normalized.Hostname()
+	}
+
+	if normalized.Path == "" {
+		normalized.Path = "/"
+	}
+
+	if normalized.RawQuery != "" {
+		normalized.RawQuery = normalized.Query().Encode()
+	}
+
+	return normalized.String()
+}
+
+func cacheKey(u *url.URL, lang string) string {
+	return normalizeUrl(u) + "\n" + strings.ToLower(lang)
+}
+
+// レスポンスのCache-ControlとExpiresからTTLを求める
+// 指定がない場合は-1を返す
+func ttlFromHeader(header http.Header, now time.Time) time.Duration {
+	maxAge := time.Duration(-1)
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache", "private":
+			return 0
+		case "s-maxage":
+			if secs, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64); err == nil {
+				// s-maxageはmax-ageより優先される
+				return max(time.Duration(secs)*time.Second, 0)
+			}
+		case "max-age":
+			if secs, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64); err == nil {
+				maxAge = max(time.Duration(secs)*time.Second, 0)
+			}
+		}
+	}
+
+	if maxAge >= 0 {
+		return maxAge
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			// 不正なExpiresは期限切れとして扱う
+			return 0
+		}
+
+		if date, err := http.ParseTime(header.Get("Date")); err == nil {
+			now = date
+		}
+		return max(expiresAt.Sub(now), 0)
+	}
+
+	return -1
+}
+
+// キャッシュしてよい期間
+// キャッシュを禁止されている場合は0を返す
+func (s *Summarizer) cacheTTL(header http.Header, now time.Time) time.Duration {
+	ttl := ttlFromHeader(header, now)
+	if ttl == 0 {
+		return 0
+	} else if ttl < 0 {
+		ttl = s.cacheMinTTL
+	}
+
+	return min(max(ttl, s.cacheMinTTL), s.cacheMaxTTL)
+}
+
+// キャッシュや他の呼び出し元と共有しないように、参照を持つフィールドも複製する
+func (s *Summary) clone() *Summary {
+	cloned := *s
+	cloned.Player.IframePermissions = slices.Clone(s.Player.IframePermissions)
+
+	if s.Article != nil {
+		article := *s.Article
+		article.Authors = slices.Clone(s.Article.Authors)
+		article.Tags = slices.Clone(s.Article.Tags)
+		cloned.Article = &article
+	}
+
+	if s.StructuredData != nil {
+		cloned.StructuredData = make([]*StructuredDataItem, len(s.StructuredData))
+		for i, item := range s.StructuredData {
+			cloned.StructuredData[i] = item.clone()
+		}
+	}
+
+	if s.Manifest != nil {
+		manifest := *s.Manifest
+		manifest.Icons = slices.Clone(s.Manifest.Icons)
+		cloned.Manifest = &manifest
+	}
+
+	return &cloned
+}
+
+func (i *StructuredDataItem) clone() *StructuredDataItem {
+	if i == nil {
+		return nil
+	}
+
+	cloned := &StructuredDataItem{Types: slices.Clone(i.Types), Id: i.Id}
+	if i.Properties != nil {
+		cloned.Properties = make(map[string][]any, len(i.Properties))
+		for name, values := range i.Properties {
+			clonedValues := make([]any, len(values))
+			for j, value := range values {
+				if item, ok := value.(*StructuredDataItem); ok {
+					clonedValues[j] = item.clone()
+				} else {
+					clonedValues[j] = value
+				}
+			}
+			cloned.Properties[name] = clonedValues
+		}
+	}
+	return cloned
+}
+
+type lruEntry struct {
+	key       string
+	summary   *Summary
+	expiresAt time.Time
+	size      int64
+}
+
+// LRUCache is an in-memory Cache that evicts the least recently used entries
+// when either the number of entries or their total size exceeds the limit.
+type LRUCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+// NewLRUCache creates an LRUCache holding at most maxEntries summaries of at most maxBytes in total.
+// A limit of zero or less means no limit.
+func NewLRUCache(maxEntries int, maxBytes int64) *LRUCache {
+	return &LRUCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get returns the summary stored for key unless it has expired.
+func (c *LRUCache) Get(key string) (*Summary, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if !time.Now().Before(entry.expiresAt) {
+		c.removeElement(elem)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(elem)
+	return entry.summary, true
+}
+
+// Set stores summary for key until expiresAt.
+func (c *LRUCache) Set(key string, summary *Summary, expiresAt time.Time) {
+	// サイズはJSONにしたときの大きさで概算する
+	encoded, err := json.Marshal(summary)
+	if err != nil {
+		return
+	}
+	size := int64(len(key) + len(encoded))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, summary: summary, expiresAt: expiresAt, size: size})
+	c.bytes += size
+
+	for (c.maxEntries > 0 && c.ll.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// Len returns the number of entries in the cache, including expired ones not yet evicted.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+func (c *LRUCache) removeElement(elem *list.Element) {
+	entry := c.ll.Remove(elem).(*lruEntry)
+	delete(c.items, entry.key)
+	c.bytes -= entry.size
+}
@@ -0,0 +1,222 @@
+package summergo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestNormalizeUrl(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "HTTPS://Example.COM", expected: "https://example.com/"},
+		{url: "https://example.com:443/path#fragment", expected: "https://example.com/path"},
+		{url: "https://example.com/?b=2&a=1", expected: "https://example.com/?a=1&b=2"},
+		{url: "https://example.com:8443/", expected: "https://example.com:8443/"},
+	}
+
+	for _, test := range tests {
+		u, err := url.Parse(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result := normalizeUrl(u); result != test.expected {
+			t.Errorf("Expected: %s, Got: %s", test.expected, result)
+		}
+	}
+}
+
+func TestTtlFromHeader(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		header   map[string]string
+		expected time.Duration
+	}{
+		{header: map[string]string{}, expected: -1},
+		{header: map[string]string{"Cache-Control": "public, max-age=3600"}, expected: time.Hour},
+		{header: map[string]string{"Cache-Control": "max-age=3600, s-maxage=60"}, expected: time.Minute},
+		{header: map[string]string{"Cache-Control": "no-store"}, expected: 0},
+		{header: map[string]string{"Expires": "Mon, 01 Jan 2024 02:00:00 GMT"}, expected: 2 * time.Hour},
+		{header: map[string]string{"Expires": "Mon, 01 Jan 2024 02:00:00 GMT", "Date": "Mon, 01 Jan 2024 01:00:00 GMT"}, expected: time.Hour},
+		{header: map[string]string{"Expires": "0"}, expected: 0},
+	}
+
+	for _, test := range tests {
+		header := http.Header{}
+		for k, v := range test.header {
+			header.Set(k, v)
+		}
+
+		if result := ttlFromHeader(header, now); result != test.expected {
+			t.Errorf("%v: Expected: %v, Got: %v", test.header, test.expected, result)
+		}
+	}
+
+	// 下限と上限で丸める
+	s := NewSummarizer(WithCacheTTL(time.Minute, time.Hour))
+	header := http.Header{}
+	header.Set("Cache-Control", "max-age=86400")
+	if result := s.cacheTTL(header, now); result != time.Hour {
+		t.Errorf("Expected: %v, Got: %v", time.Hour, result)
+	}
+	header = http.Header{}
+	if result := s.cacheTTL(header, now); result != time.Minute {
+		t.Errorf("Expected: %v, Got: %v", time.Minute, result)
+	}
+
+	// キャッシュを禁止されている場合は下限を適用しない
+	header.Set("Cache-Control", "no-cache")
+	if result := s.cacheTTL(header, now); result != 0 {
+		t.Errorf("Expected: %v, Got: %v", 0, result)
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+
+	// エントリ数で制限
+	cache := NewLRUCache(2, 0)
+	cache.Set("a", &Summary{Title: "A"}, expiresAt)
+	cache.Set("b", &Summary{Title: "B"}, expiresAt)
+	cache.Get("a")
+	cache.Set("c", &Summary{Title: "C"}, expiresAt)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("least recently used entry should be evicted")
+	}
+	if summary, ok := cache.Get("a"); !ok || summary.Title != "A" {
+		t.Errorf("recently used entry should be kept")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Expected: %d, Got: %d", 2, cache.Len())
+	}
+
+	// 期限切れ
+	cache.Set("expired", &Summary{Title: "Expired"}, time.Now().Add(-time.Second))
+	if _, ok := cache.Get("expired"); ok {
+		t.Errorf("expired entry should not be returned")
+	}
+
+	// バイト数で制限
+	encoded, _ := json.Marshal(&Summary{Title: "A"})
+	size := int64(len("a") + len(encoded))
+	cache = NewLRUCache(0, size+size/2)
+	cache.Set("a", &Summary{Title: "A"}, expiresAt)
+	cache.Set("b", &Summary{Title: "B"}, expiresAt)
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("entry should be evicted when the total size exceeds the limit")
+	}
+	if _, ok := cache.Get("b"); !ok {
+		t.Errorf("latest entry should be kept")
+	}
+}
+
+func TestSummarizerCache(t *testing.T) {
+	requests := 0
+	s := NewSummarizer(
+		WithOEmbed(false),
+		WithCache(NewLRUCache(10, 0)),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			resp := newTestResponse(req, 200, "text/html; charset=utf-8", testPageHtml)
+			resp.Header.Set("Cache-Control", "max-age=3600")
+			return resp, nil
+		})),
+	)
+
+	for _, u := range []string{"https://example.com/page", "https://EXAMPLE.com/page#top"} {
+		summary, err := s.Summarize(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summary.Title != "Test Page" {
+			t.Errorf("Expected: %s, Got: %s", "Test Page", summary.Title)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected: %d, Got: %d", 1, requests)
+	}
+
+	// 言語が違う場合は別のキャッシュ
+	if _, err := s.SummarizeContext(ContextWithLanguage(t.Context(), "ja-JP"), "https://example.com/page"); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected: %d, Got: %d", 2, requests)
+	}
+}
+
+func TestSummarizerCacheNoStore(t *testing.T) {
+	requests := 0
+	s := NewSummarizer(
+		WithOEmbed(false),
+		WithCache(NewLRUCache(10, 0)),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			resp := newTestResponse(req, 200, "text/html; charset=utf-8", testPageHtml)
+			resp.Header.Set("Cache-Control", "no-store")
+			return resp, nil
+		})),
+	)
+
+	for range 2 {
+		if _, err := s.Summarize("https://example.com/page"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if requests != 2 {
+		t.Errorf("Expected: %d, Got: %d", 2, requests)
+	}
+}
+
+func TestSummarizerCacheIsolation(t *testing.T) {
+	page := `<html><head>
+		<title>Article</title>
+		<meta property="article:tag" content="Go">
+		<script type="application/ld+json">{"@type": "NewsArticle", "headline": "Headline"}</script>
+	</head><body></body></html>`
+
+	s := NewSummarizer(
+		WithOEmbed(false),
+		WithManifest(false),
+		WithCache(NewLRUCache(10, 0)),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return newTestResponse(req, 200, "text/html; charset=utf-8", page), nil
+		})),
+	)
+
+	summary, err := s.Summarize("https://example.com/article")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 呼び出し元が書き換えてもキャッシュには影響しない
+	summary.Article.Tags[0] = "Modified"
+	summary.StructuredData[0].Properties["headline"][0] = "Modified"
+
+	summary, err = s.Summarize("https://example.com/article")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Article.Tags[0] != "Go" {
+		t.Errorf("Expected: %s, Got: %s", "Go", summary.Article.Tags[0])
+	}
+	if headline := summary.StructuredData[0].String("headline"); headline != "Headline" {
+		t.Errorf("Expected: %s, Got: %s", "Headline", headline)
+	}
+
+	// 取得した直後のものも同様
+	summary.Article.Tags[0] = "Modified"
+	summary, _ = s.Summarize("https://example.com/article")
+	if summary.Article.Tags[0] != "Go" {
+		t.Errorf("Expected: %s, Got: %s", "Go", summary.Article.Tags[0])
+	}
+}
@@ -1,53 +1,203 @@
 package summergo
 
 import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/saintfish/chardet"
 	"golang.org/x/net/html"
-	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
-	"strings"
 )
 
-func isShiftJis(doc *html.Node) bool {
-	contentType := analyzeNode(doc, []*findParam{
-		{tagName: "meta", attrKey: "http-equiv", attrValue: "Content-Type", targetKey: "content"},
-		{tagName: "meta", attrKey: "http-equiv", attrValue: "content-type", targetKey: "content"},
-		{tagName: "meta", attrKey: "charset", attrValue: "Shift_JIS", targetKey: "charset"},
-		{tagName: "meta", attrKey: "charset", attrValue: "shift_jis", targetKey: "charset"},
-	}...)
-
-	if strings.Contains(strings.ToLower(contentType), "shift_jis") {
-		return true
-	} else {
+const (
+	// WHATWGの仕様ではmetaタグの検索は先頭1024バイトまで
+	prescanSize = 1024
+	// 本文から推測する場合に読む大きさ
+	detectSize = 64 * 1024
+)
+
+// chardetとWHATWGでラベルが異なるもの
+var chardetLabels = map[string]string{
+	"GB-18030": "gb18030",
+}
+
+// Content-Typeヘッダーからcharsetを取り出す
+func charsetFromContentType(contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return params["charset"]
+}
+
+// BOMから文字コードを判定する
+func encodingFromBom(content []byte) (encoding.Encoding, string) {
+	if bytes.HasPrefix(content, []byte{0xEF, 0xBB, 0xBF}) {
+		return unicode.UTF8BOM, "utf-8"
+	} else if bytes.HasPrefix(content, []byte{0xFE, 0xFF}) {
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), "utf-16be"
+	} else if bytes.HasPrefix(content, []byte{0xFF, 0xFE}) {
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), "utf-16le"
+	}
+	return nil, ""
+}
+
+// 先頭のmetaタグからcharsetを探す
+func prescanCharset(content []byte) string {
+	if len(content) > prescanSize {
+		content = content[:prescanSize]
+	}
+
+	z := html.NewTokenizer(bytes.NewReader(content))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) != "meta" || !hasAttr {
+				continue
+			}
+
+			var httpEquiv, content, metaCharset string
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				switch string(key) {
+				case "charset":
+					metaCharset = string(val)
+				case "http-equiv":
+					httpEquiv = string(val)
+				case "content":
+					content = string(val)
+				}
+			}
+
+			if metaCharset != "" {
+				return metaCharset
+			} else if strings.EqualFold(httpEquiv, "content-type") {
+				if label := charsetFromContentType(content); label != "" {
+					return label
+				}
+			}
+		}
+	}
+}
+
+// ISO-2022-JPで文字集合を切り替えるエスケープシーケンス
+// 7ビットなのでUTF-8としても正しいバイト列になる
+var iso2022JpEscapes = [][]byte{
+	[]byte("\x1b$@"),
+	[]byte("\x1b$B"),
+	[]byte("\x1b$(D"),
+	[]byte("\x1b(J"),
+	[]byte("\x1b(I"),
+}
+
+func hasIso2022JpEscape(content []byte) bool {
+	if bytes.IndexByte(content, 0x1b) < 0 {
 		return false
 	}
+	for _, escape := range iso2022JpEscapes {
+		if bytes.Contains(content, escape) {
+			return true
+		}
+	}
+	return false
 }
 
-func convertShiftJisToUtf8(str string) string {
-	encoder := japanese.ShiftJIS.NewDecoder()
+// 本文から文字コードを推測する
+func detectCharset(content []byte) string {
+	if hasIso2022JpEscape(content) {
+		return "iso-2022-jp"
+	}
+
+	if utf8.Valid(content) {
+		return "utf-8"
+	}
 
-	// 文字列をShift JISからUTF-8に変換
-	utf8Bytes, _, err := transform.Bytes(encoder, []byte(str))
+	result, err := chardet.NewHtmlDetector().DetectBest(content)
 	if err != nil {
 		return ""
 	}
 
-	// UTF-8のバイト列を文字列に変換
-	utf8Str := string(utf8Bytes)
+	if label, ok := chardetLabels[result.Charset]; ok {
+		return label
+	}
+	return result.Charset
+}
+
+// BOM、Content-Type、metaタグの順に文字コードを判定する
+func sniffEncoding(content []byte, label string) (encoding.Encoding, string) {
+	if e, name := encodingFromBom(content); e != nil {
+		return e, name
+	}
+
+	if label != "" {
+		if e, name := charset.Lookup(label); e != nil {
+			return e, name
+		}
+	}
 
-	return utf8Str
+	if label := prescanCharset(content); label != "" {
+		if e, name := charset.Lookup(label); e != nil {
+			// metaタグでUTF-16が指定されていてもASCII互換として読めているのでUTF-8として扱う
+			if strings.HasPrefix(name, "utf-16") {
+				return encoding.Nop, "utf-8"
+			}
+			return e, name
+		}
+	}
+
+	return nil, ""
 }
 
-func convertEucJpToUtf8(str string) string {
-	encoder := japanese.EUCJP.NewDecoder()
+// determineEncoding determines the encoding of an HTML document following the WHATWG encoding sniffing algorithm:
+// BOM, the transport layer charset label, <meta> prescan, then frequency analysis of the content.
+func determineEncoding(content []byte, label string) (encoding.Encoding, string) {
+	if e, name := sniffEncoding(content, label); e != nil {
+		return e, name
+	}
 
-	// 文字列をEUC-JPからUTF-8に変換
-	utf8Bytes, _, err := transform.Bytes(encoder, []byte(str))
-	if err != nil {
-		return ""
+	if label := detectCharset(content); label != "" {
+		if e, name := charset.Lookup(label); e != nil {
+			return e, name
+		}
 	}
 
-	// UTF-8のバイト列を文字列に変換
-	utf8Str := string(utf8Bytes)
+	return encoding.Nop, "utf-8"
+}
+
+// newDecodingReader returns a reader that converts an HTML document read from r to UTF-8.
+// label is the charset from the transport layer and may be empty.
+// Only the beginning of the document is read to determine the encoding.
+func newDecodingReader(r io.Reader, label string) (io.Reader, error) {
+	br := bufio.NewReaderSize(r, detectSize)
 
-	return utf8Str
+	content, err := br.Peek(prescanSize)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	e, name := sniffEncoding(content, label)
+	if e == nil {
+		// 指定がなければ多めに読んで推測する
+		content, err = br.Peek(detectSize)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		e, name = determineEncoding(content, "")
+	}
+
+	if name == "utf-8" && e != unicode.UTF8BOM {
+		return br, nil
+	}
+	return transform.NewReader(br, e.NewDecoder()), nil
 }
@@ -1,48 +1,147 @@
 package summergo
 
 import (
-	"golang.org/x/net/html"
+	"bytes"
+	"io"
+	"net/url"
 	"strings"
 	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
 )
 
-func TestIsShiftJis(t *testing.T) {
-	// 滅ぼすべきサイト
-	htmlString := `<html>
-					  <head>
-						<meta http-equiv="Content-Type" content="text/html; charset=Shift_JIS">
-					  </head>
-					</html>`
-	doc, err := html.Parse(strings.NewReader(htmlString))
+func encodeString(t *testing.T, e encoding.Encoding, str string) []byte {
+	encoded, err := e.NewEncoder().String(str)
 	if err != nil {
 		t.Fatal(err)
 	}
+	return []byte(encoded)
+}
+
+func TestDetermineEncoding(t *testing.T) {
+	tests := []struct {
+		content  string
+		label    string
+		expected string
+	}{
+		// BOMが最優先
+		{content: "\xEF\xBB\xBF<html></html>", label: "shift_jis", expected: "utf-8"},
+		{content: "\xFF\xFE<\x00h\x00", expected: "utf-16le"},
+		// Content-Type
+		{content: "<html></html>", label: "Shift_JIS", expected: "shift_jis"},
+		{content: `<meta charset="euc-jp">`, label: "big5", expected: "big5"},
+		// metaタグ
+		{content: `<html><head><meta charset="EUC-JP"></head></html>`, expected: "euc-jp"},
+		{content: `<html><head><meta http-equiv="Content-Type" content="text/html; charset=Shift_JIS"></head></html>`, expected: "shift_jis"},
+		{content: `<html><head><meta charset="utf-16"></head></html>`, expected: "utf-8"},
+		{content: `<html><head><meta charset="gbk"></head></html>`, expected: "gbk"},
+		{content: `<html><head><meta charset="iso-2022-jp"></head></html>`, expected: "iso-2022-jp"},
+		{content: `<html><head><meta charset="windows-1251"></head></html>`, expected: "windows-1251"},
+		// 不明なラベルは無視する
+		{content: "<html></html>", label: "unknown-charset", expected: "utf-8"},
+	}
+
+	for _, test := range tests {
+		_, name := determineEncoding([]byte(test.content), test.label)
+		if name != test.expected {
+			t.Errorf("%q: Expected: %s, Got: %s", test.content, test.expected, name)
+		}
+	}
+}
+
+func TestDetermineEncodingByContent(t *testing.T) {
+	tests := []struct {
+		encoding encoding.Encoding
+		text     string
+		expected string
+	}{
+		{encoding: japanese.ShiftJIS, text: "日本語のテキストです。文字コードを自動で判定できるかテストしています。", expected: "shift_jis"},
+		{encoding: japanese.EUCJP, text: "日本語のテキストです。文字コードを自動で判定できるかテストしています。", expected: "euc-jp"},
+		{encoding: simplifiedchinese.GB18030, text: "这是一个简体中文的测试文本。我们正在测试是否可以自动检测字符编码。", expected: "gb18030"},
+		{encoding: traditionalchinese.Big5, text: "這是一個繁體中文的測試文本。我們正在測試是否可以自動檢測字元編碼。", expected: "big5"},
+		{encoding: japanese.ISO2022JP, text: "日本語のテキストです。文字コードを自動で判定できるかテストしています。", expected: "iso-2022-jp"},
+		{encoding: korean.EUCKR, text: "이것은 한국어 테스트 문장입니다. 문자 인코딩을 자동으로 감지할 수 있는지 테스트하고 있습니다.", expected: "euc-kr"},
+	}
 
-	if !isShiftJis(doc) {
-		t.Errorf("Expected: true, Got: false")
+	for _, test := range tests {
+		content := encodeString(t, test.encoding, "<html><head><title>"+test.text+"</title></head><body><p>"+strings.Repeat(test.text, 4)+"</p></body></html>")
+		_, name := determineEncoding(content, "")
+		if name != test.expected {
+			t.Errorf("Expected: %s, Got: %s", test.expected, name)
+		}
 	}
+}
 
-	// Shift JISでないコンテンツのHTML
-	htmlString = `<html>
-					  <head>
-						<meta http-equiv="Content-Type" content="text/html; charset=UTF-8">
-					  </head>
-					</html>`
-	doc, err = html.Parse(strings.NewReader(htmlString))
+func readDecoded(t *testing.T, content []byte, label string) string {
+	r, err := newDecodingReader(bytes.NewReader(content), label)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if isShiftJis(doc) {
-		t.Errorf("Expected: false, Got: true")
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
 	}
+	return string(decoded)
 }
 
-func TestConvertShiftJisToUtf8(t *testing.T) {
-	shiftJisStr := "\x82\xa0\x82\xa2\x82\xa4" // "あいう"
-	result := convertShiftJisToUtf8(shiftJisStr)
-	expected := "あいう"
+func TestNewDecodingReader(t *testing.T) {
+	content := []byte("<title>\x82\xa0\x82\xa2\x82\xa4</title>") // "あいう"
+	result := readDecoded(t, content, "shift_jis")
+	expected := "<title>あいう</title>"
+	if result != expected {
+		t.Errorf("Expected: %s, Got: %s", expected, result)
+	}
+
+	// UTF-8はそのまま
+	result = readDecoded(t, []byte(expected), "")
+	if result != expected {
+		t.Errorf("Expected: %s, Got: %s", expected, result)
+	}
+
+	// BOMは取り除く
+	result = readDecoded(t, append([]byte{0xEF, 0xBB, 0xBF}, expected...), "")
 	if result != expected {
 		t.Errorf("Expected: %s, Got: %s", expected, result)
 	}
+
+	// metaタグの指定が先頭1024バイトより後ろにあっても本文から推測する
+	content = encodeString(t, japanese.EUCJP, "<html><head><!--"+strings.Repeat(" ", 2048)+"--><title>日本語のタイトルです。文字コードを判定します。</title></head></html>")
+	result = readDecoded(t, content, "")
+	if !strings.Contains(result, "日本語のタイトルです。") {
+		t.Errorf("failed to decode: %s", result)
+	}
+}
+
+func TestSummarizeHtmlCharset(t *testing.T) {
+	title := "这是一个简体中文的测试标题"
+	content := encodeString(t, simplifiedchinese.GBK, `<html><head><meta charset="gbk"><title>`+title+`</title></head></html>`)
+
+	siteUrl, _ := url.Parse("https://example.com/")
+	summary, err := NewSummarizer(WithOEmbed(false)).SummarizeHtml(*siteUrl, bytes.NewReader(content), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Title != title {
+		t.Errorf("Expected: %s, Got: %s", title, summary.Title)
+	}
+}
+
+func TestSummarizeHtmlIso2022Jp(t *testing.T) {
+	// 文字コードの指定がないISO-2022-JPのページ
+	title := "日本語のタイトル"
+	content := encodeString(t, japanese.ISO2022JP, `<html><head><title>`+title+`</title></head><body><p>本文です。</p></body></html>`)
+
+	siteUrl, _ := url.Parse("https://example.com/")
+	summary, err := NewSummarizer(WithOEmbed(false), WithManifest(false)).SummarizeHtml(*siteUrl, bytes.NewReader(content), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Title != title {
+		t.Errorf("Expected: %s, Got: %s", title, summary.Title)
+	}
 }
@@ -0,0 +1,134 @@
+// Command summergo-server serves the Misskey summaly API backed by summergo.
+//
+//	GET /?url=https://example.com/&lang=ja-JP
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/nexryai/summergo"
+)
+
+type errorBody struct {
+	Name       string `json:"name"`
+	Message    string `json:"message"`
+	StatusCode int    `json:"statusCode,omitempty"`
+}
+
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+func writeJson(w http.ResponseWriter, statusCode int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJson(w, statusCode, &errorResponse{Error: errorBody{Name: "Error", Message: message}})
+}
+
+// summergoのエラーをレスポンスに変換する
+func writeSummarizeError(w http.ResponseWriter, err error) {
+	body := errorBody{Name: "Error", Message: err.Error()}
+	statusCode := http.StatusInternalServerError
+
+	var statusErr *summergo.HTTPStatusError
+	switch {
+	case errors.Is(err, summergo.ErrInvalidURL):
+		statusCode = http.StatusBadRequest
+	case errors.Is(err, summergo.ErrBlockedAddress):
+		statusCode = http.StatusForbidden
+	case errors.As(err, &statusErr):
+		body.Name = "StatusError"
+		body.StatusCode = statusErr.StatusCode
+		statusCode = http.StatusBadGateway
+	case errors.Is(err, summergo.ErrTimeout):
+		statusCode = http.StatusGatewayTimeout
+	case errors.Is(err, summergo.ErrBodyTooLarge):
+		statusCode = http.StatusBadGateway
+	case errors.Is(err, summergo.ErrNotHTML):
+		statusCode = http.StatusUnprocessableEntity
+	case errors.Is(err, context.Canceled):
+		// クライアントが切断しているので返しても届かない
+		return
+	}
+
+	writeJson(w, statusCode, &errorResponse{Error: body})
+}
+
+func newHandler(s *summergo.Summarizer) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		} else if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		query := r.URL.Query()
+		siteUrl := query.Get("url")
+		if siteUrl == "" {
+			writeError(w, http.StatusBadRequest, "url is required")
+			return
+		}
+
+		ctx := r.Context()
+		if lang := query.Get("lang"); lang != "" {
+			ctx = summergo.ContextWithLanguage(ctx, lang)
+		}
+
+		summary, err := s.SummarizeContext(ctx, siteUrl)
+		if err != nil {
+			writeSummarizeError(w, err)
+			return
+		}
+
+		writeJson(w, http.StatusOK, summary)
+	})
+
+	return mux
+}
+
+func main() {
+	addr := flag.String("addr", ":3000", "listen address")
+	timeout := flag.Duration("timeout", 10*time.Second, "deadline for summarizing one page")
+	maxSize := flag.Int64("max-size", 1024*1024*10, "maximum response body size in bytes")
+	userAgent := flag.String("user-agent", "", "default User-Agent header")
+	flag.Parse()
+
+	if port := os.Getenv("PORT"); port != "" {
+		*addr = ":" + port
+	}
+
+	opts := []summergo.Option{
+		summergo.WithTimeout(*timeout),
+		summergo.WithMaxBodySize(*maxSize),
+	}
+	if *userAgent != "" {
+		opts = append(opts, summergo.WithUserAgent(*userAgent))
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           newHandler(summergo.NewSummarizer(opts...)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("listening on %s", *addr)
+	log.Fatal(server.ListenAndServe())
+}
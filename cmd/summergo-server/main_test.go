@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nexryai/summergo"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newTestHandler(t *testing.T) http.Handler {
+	return newHandler(summergo.NewSummarizer(
+		summergo.WithOEmbed(false),
+		summergo.WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/missing" {
+				return &http.Response{StatusCode: 404, Status: "404 Not Found", Header: http.Header{}, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+			}
+
+			if req.URL.Path == "/image.png" {
+				header := http.Header{}
+				header.Set("Content-Type", "image/png")
+				return &http.Response{StatusCode: 200, Status: "200 OK", Header: header, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+			}
+
+			if req.Header.Get("Accept-Language") != "ja-JP" {
+				t.Errorf("Expected: %s, Got: %s", "ja-JP", req.Header.Get("Accept-Language"))
+			}
+
+			header := http.Header{}
+			header.Set("Content-Type", "text/html; charset=utf-8")
+			body := `<html><head><title>Test Page</title><meta property="og:description" content="Test Description"></head></html>`
+			return &http.Response{StatusCode: 200, Status: "200 OK", Header: header, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+		})),
+	))
+}
+
+func TestHandler(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/?url=https://example.com/&lang=ja-JP", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected: %d, Got: %d (%s)", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	summary := &summergo.Summary{}
+	if err := json.Unmarshal(rec.Body.Bytes(), summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Title != "Test Page" {
+		t.Errorf("Expected: %s, Got: %s", "Test Page", summary.Title)
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	handler := newTestHandler(t)
+
+	tests := []struct {
+		method     string
+		target     string
+		statusCode int
+	}{
+		{method: "GET", target: "/", statusCode: http.StatusBadRequest},
+		{method: "POST", target: "/?url=https://example.com/", statusCode: http.StatusMethodNotAllowed},
+		{method: "GET", target: "/other", statusCode: http.StatusNotFound},
+		{method: "GET", target: "/?url=https://example.com/missing", statusCode: http.StatusBadGateway},
+		{method: "GET", target: "/?url=https://example.com/image.png", statusCode: http.StatusUnprocessableEntity},
+		{method: "GET", target: "/?url=not-a-url", statusCode: http.StatusBadRequest},
+		{method: "GET", target: "/?url=https://192.168.1.1/", statusCode: http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(test.method, test.target, nil))
+		if rec.Code != test.statusCode {
+			t.Errorf("%s %s: Expected: %d, Got: %d", test.method, test.target, test.statusCode, rec.Code)
+		}
+
+		body := &errorResponse{}
+		if err := json.Unmarshal(rec.Body.Bytes(), body); err != nil || body.Error.Message == "" {
+			t.Errorf("%s %s: error response should have a message: %s", test.method, test.target, rec.Body.String())
+		}
+	}
+}
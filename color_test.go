@@ -0,0 +1,76 @@
+package summergo
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCssColor(t *testing.T) {
+	tests := map[string]string{
+		"#ABC":                          "#aabbcc",
+		"#abcd":                         "#aabbccdd",
+		"#336699":                       "#336699",
+		" #336699FF ":                   "#336699",
+		"RebeccaPurple":                 "#663399",
+		"transparent":                   "#00000000",
+		"rgb(255, 0, 128)":              "#ff0080",
+		"rgba(255, 0, 128, 0.5)":        "#ff008080",
+		"rgb(0 128 255 / 50%)":          "#0080ff80",
+		"rgb(100%, 50%, 0%)":            "#ff8000",
+		"rgb(300, -20, 0)":              "#ff0000",
+		"hsl(120, 100%, 25%)":           "#008000",
+		"hsl(120deg 100% 25%)":          "#008000",
+		"hsla(0.5turn, 100%, 50%, 0.5)": "#00ffff80",
+		"hsl(-120, 100%, 50%)":          "#0000ff",
+		"hsl(200grad 100% 50%)":         "#00ffff",
+	}
+
+	for value, expected := range tests {
+		result, ok := normalizeCssColor(value)
+		if !ok || result != expected {
+			t.Errorf("%q: Expected: %s, Got: %s", value, expected, result)
+		}
+	}
+
+	for _, value := range []string{"", "currentColor", "#12345", "#ggg", "notacolor", "rgb(1, 2)", "rgb(1, 2, 3 / 0.5)", "rgb(a b c)", "var(--brand)"} {
+		if result, ok := normalizeCssColor(value); ok {
+			t.Errorf("%q should be invalid, Got: %s", value, result)
+		}
+	}
+}
+
+func TestSummarizeHtmlThemeColor(t *testing.T) {
+	siteUrl, _ := url.Parse("https://example.com/")
+	s := NewSummarizer(WithOEmbed(false), WithManifest(false))
+
+	page := `<html><head>
+		<title>Title</title>
+		<meta name="theme-color" content="invalid">
+		<meta name="theme-color" media="(prefers-color-scheme: dark)" content="rgb(0 0 0)">
+		<meta name="theme-color" media="(prefers-color-scheme: light)" content="white">
+		<meta name="theme-color" content="red">
+		<meta name="msapplication-TileColor" content="#123456">
+	</head><body></body></html>`
+
+	summary, err := s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.ThemeColor != "#ffffff" {
+		t.Errorf("Expected: %s, Got: %s", "#ffffff", summary.ThemeColor)
+	}
+	if summary.ThemeColorDark != "#000000" {
+		t.Errorf("Expected: %s, Got: %s", "#000000", summary.ThemeColorDark)
+	}
+
+	// theme-colorがなければmsapplication-TileColorを使う
+	page = `<title>Title</title><meta name="msapplication-TileColor" content="#123456">`
+	summary, err = s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.ThemeColor != "#123456" || summary.ThemeColorDark != "" {
+		t.Errorf("unexpected theme colors: %s, %s", summary.ThemeColor, summary.ThemeColorDark)
+	}
+}
@@ -0,0 +1,66 @@
+package summergo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/nexryai/archer"
+)
+
+var (
+	// ErrInvalidURL is returned when the requested URL cannot be parsed or is not an http(s) URL.
+	ErrInvalidURL = errors.New("invalid url")
+	// ErrBlockedAddress is returned when the request is blocked to prevent SSRF.
+	ErrBlockedAddress = errors.New("blocked address")
+	// ErrTimeout is returned when the deadline is exceeded while summarizing.
+	ErrTimeout = errors.New("timed out")
+	// ErrBodyTooLarge is returned when the response body exceeds the size limit.
+	ErrBodyTooLarge = errors.New("response body too large")
+	// ErrNotHTML is returned when the response is not an HTML document.
+	ErrNotHTML = errors.New("response is not html")
+)
+
+// HTTPStatusError is returned when the server responds with a non-200 status code.
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return "non-200 status code: " + e.Status
+}
+
+// archerやnet/httpのエラーを公開しているエラーに変換する
+// 元のエラーもerrors.Isで判別できるようにラップしておく
+func wrapRequestError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, archer.ErrUnsafeUrlDetected) || errors.Is(err, archer.ErrPrivateAddressDetected) || errors.Is(err, archer.ErrBlockedByDNS) {
+		return fmt.Errorf("%w: %w", ErrBlockedAddress, err)
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+
+	return err
+}
+
+func isHtmlContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
@@ -0,0 +1,68 @@
+package summergo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nexryai/archer"
+)
+
+func TestSummarizeErrors(t *testing.T) {
+	s := NewSummarizer(
+		WithTimeout(100*time.Millisecond),
+		WithMaxBodySize(1024),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			switch req.URL.Path {
+			case "/404":
+				return newTestResponse(req, 404, "text/html", ""), nil
+			case "/image.png":
+				return newTestResponse(req, 200, "image/png", ""), nil
+			case "/large":
+				resp := newTestResponse(req, 200, "text/html", "")
+				resp.Header.Set("Content-Length", "4096")
+				return resp, nil
+			case "/slow":
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			}
+			return newTestResponse(req, 200, "text/html", testPageHtml), nil
+		})),
+	)
+
+	tests := []struct {
+		url    string
+		expect error
+	}{
+		{url: "://invalid", expect: ErrInvalidURL},
+		{url: "ftp://example.com/", expect: ErrInvalidURL},
+		{url: "https://192.168.1.1/", expect: ErrBlockedAddress},
+		{url: "https://example.com/image.png", expect: ErrNotHTML},
+		{url: "https://example.com/large", expect: ErrBodyTooLarge},
+		{url: "https://example.com/slow", expect: ErrTimeout},
+	}
+
+	for _, test := range tests {
+		_, err := s.SummarizeContext(context.Background(), test.url)
+		if !errors.Is(err, test.expect) {
+			t.Errorf("%s: Expected: %v, Got: %v", test.url, test.expect, err)
+		}
+	}
+
+	// archerのエラーも判別できる
+	_, err := s.Summarize("https://192.168.1.1/")
+	if !errors.Is(err, archer.ErrUnsafeUrlDetected) {
+		t.Errorf("Expected: %v, Got: %v", archer.ErrUnsafeUrlDetected, err)
+	}
+
+	_, err = s.Summarize("https://example.com/404")
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Expected: *HTTPStatusError, Got: %v", err)
+	}
+	if statusErr.StatusCode != 404 {
+		t.Errorf("Expected: %d, Got: %d", 404, statusErr.StatusCode)
+	}
+}
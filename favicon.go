@@ -0,0 +1,284 @@
+package summergo
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultIconSize = 64
+	// 存在を確認するアイコンの最大数
+	maxIconChecks = 4
+)
+
+// サイズの指定がないアイコンは一般的な大きさとみなす
+const (
+	defaultFaviconSize   = 16
+	defaultTouchIconSize = 180
+	defaultManifestSize  = 192
+)
+
+const (
+	// 単色のアイコンは他に候補がない場合だけ使う
+	maskIconPenalty = 1 << 20
+	// 拡大は縮小より粗くなる
+	upscalePenalty = 4
+)
+
+// アイコンの候補
+type iconCandidate struct {
+	url string
+	// 一辺の大きさの一覧
+	sizes []int
+	// SVGなど任意の大きさで表示できるもの
+	scalable bool
+	// Safariのピン留め用の単色アイコン
+	mask bool
+	// 余白を含むマスク用のアイコン
+	maskable     bool
+	fallbackSize int
+}
+
+// sizes属性を解析する
+// "any"が含まれていればscalableを返す
+func parseIconSizes(value string) ([]int, bool) {
+	var sizes []int
+	scalable := false
+
+	for _, size := range strings.Fields(strings.ToLower(value)) {
+		if size == "any" {
+			scalable = true
+			continue
+		}
+
+		w, h, ok := strings.Cut(size, "x")
+		if !ok {
+			continue
+		}
+		width, err := strconv.Atoi(w)
+		if err != nil || width <= 0 {
+			continue
+		}
+		height, err := strconv.Atoi(h)
+		if err != nil || height <= 0 {
+			continue
+		}
+
+		sizes = append(sizes, max(width, height))
+	}
+
+	return sizes, scalable
+}
+
+// 画像以外が指定されているものは候補にしない
+func isIconType(mimeType string) bool {
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	return mimeType == "" || strings.HasPrefix(mimeType, "image/")
+}
+
+func isSvgIcon(iconUrl, mimeType string) bool {
+	if strings.EqualFold(strings.TrimSpace(mimeType), "image/svg+xml") {
+		return true
+	}
+
+	u, err := url.Parse(iconUrl)
+	return err == nil && strings.EqualFold(path.Ext(u.Path), ".svg")
+}
+
+// ページのlinkタグからアイコンの候補を集める
+func getIconCandidates(idx *metaIndex, base *url.URL) []*iconCandidate {
+	var candidates []*iconCandidate
+
+	for _, e := range idx.links {
+		iconUrl := resolveUrl(base, e.attr("href"))
+		if iconUrl == "" || !isIconType(e.attr("type")) {
+			continue
+		}
+
+		candidate := &iconCandidate{url: iconUrl}
+		for _, rel := range strings.Fields(strings.ToLower(e.attr("rel"))) {
+			switch rel {
+			case "icon":
+				candidate.fallbackSize = defaultFaviconSize
+			case "apple-touch-icon", "apple-touch-icon-precomposed":
+				candidate.fallbackSize = defaultTouchIconSize
+			case "mask-icon":
+				candidate.fallbackSize = defaultFaviconSize
+				candidate.mask = true
+			}
+		}
+		if candidate.fallbackSize == 0 {
+			continue
+		}
+
+		candidate.sizes, candidate.scalable = parseIconSizes(e.attr("sizes"))
+		if isSvgIcon(iconUrl, e.attr("type")) {
+			candidate.scalable = true
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates
+}
+
+// マニフェストのiconsからアイコンの候補を集める
+func getManifestIconCandidates(manifest *Manifest) []*iconCandidate {
+	if manifest == nil {
+		return nil
+	}
+
+	var candidates []*iconCandidate
+	for _, icon := range manifest.Icons {
+		iconUrl := icon.Src
+		if !isIconType(icon.Type) {
+			continue
+		}
+
+		candidate := &iconCandidate{url: iconUrl, fallbackSize: defaultManifestSize}
+
+		purposes := strings.Fields(strings.ToLower(icon.Purpose))
+		if len(purposes) > 0 && !slices.Contains(purposes, "any") {
+			// 単色のアイコンはそのままでは表示できない
+			if !slices.Contains(purposes, "maskable") {
+				continue
+			}
+			candidate.maskable = true
+		}
+
+		candidate.sizes, candidate.scalable = parseIconSizes(icon.Sizes)
+		if isSvgIcon(iconUrl, icon.Type) {
+			candidate.scalable = true
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates
+}
+
+// targetの大きさで表示するのにもっとも適した大きさ
+// targetより大きいものの中で最小のもの、なければ最大のものを返す
+func (c *iconCandidate) bestSize(target int) int {
+	if c.scalable {
+		return target
+	} else if len(c.sizes) == 0 {
+		return c.fallbackSize
+	}
+
+	best := 0
+	for _, size := range c.sizes {
+		if best == 0 ||
+			(size >= target && (best < target || size < best)) ||
+			(size < target && best < target && size > best) {
+			best = size
+		}
+	}
+	return best
+}
+
+// 小さいほど望ましい
+func (c *iconCandidate) penalty(target int) int {
+	size := c.bestSize(target)
+
+	var p int
+	if size >= target {
+		p = size - target
+	} else {
+		p = (target - size) * upscalePenalty
+	}
+
+	if c.mask {
+		p += maskIconPenalty
+	} else if c.maskable {
+		p += target
+	}
+
+	return p
+}
+
+// アイコンを望ましい順に並べる
+// 同じ評価のものは元の順序を保つ
+func rankIcons(candidates []*iconCandidate, target int) []*iconCandidate {
+	ranked := slices.Clone(candidates)
+	slices.SortStableFunc(ranked, func(a, b *iconCandidate) int {
+		return a.penalty(target) - b.penalty(target)
+	})
+	return ranked
+}
+
+// アイコンが実際に取得できるか確認する
+func (s *Summarizer) iconExists(ctx context.Context, iconUrl string) bool {
+	// data:などはリクエストせずに使う
+	if u, err := url.Parse(iconUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return err == nil
+	}
+
+	for _, method := range []string{"HEAD", "GET"} {
+		req, newReqErr := http.NewRequestWithContext(ctx, method, iconUrl, nil)
+		if newReqErr != nil {
+			return false
+		}
+
+		req.Header.Set("User-Agent", s.userAgent)
+
+		resp, respErr := s.send(req)
+		if respErr != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+
+		// HEADに対応していないサーバーもある
+		if method == "HEAD" && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+			continue
+		}
+
+		// 見つからない場合にHTMLのページを返すサーバーもある
+		// Content-Typeなしで配信されるアイコンもあるので、明示的にHTMLの場合だけ弾く
+		contentType := resp.Header.Get("Content-Type")
+		return resp.StatusCode >= 200 && resp.StatusCode < 300 && (contentType == "" || !isHtmlContentType(contentType))
+	}
+
+	return false
+}
+
+// ページとマニフェストのアイコンからもっとも適したものを選ぶ
+func (s *Summarizer) getFavicon(ctx context.Context, idx *metaIndex, base *url.URL, docUrl *url.URL, manifest *Manifest) string {
+	candidates := append(getIconCandidates(idx, base), getManifestIconCandidates(manifest)...)
+	ranked := rankIcons(candidates, s.iconSize)
+
+	// 指定がなければブラウザと同じくページのオリジンの/favicon.icoを使う
+	// 単色のアイコンよりは優先する
+	favicon := resolveUrl(docUrl, "/favicon.ico")
+	if !slices.ContainsFunc(ranked, func(c *iconCandidate) bool { return c.url == favicon }) {
+		i := slices.IndexFunc(ranked, func(c *iconCandidate) bool { return c.mask })
+		if i < 0 {
+			i = len(ranked)
+		}
+		ranked = slices.Insert(ranked, i, &iconCandidate{url: favicon})
+	}
+
+	if !s.verifyIcon {
+		return ranked[0].url
+	}
+
+	for i, candidate := range ranked {
+		// /favicon.icoは必ず確認する
+		if i >= maxIconChecks && candidate.url != favicon {
+			continue
+		}
+		if s.iconExists(ctx, candidate.url) {
+			return candidate.url
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,143 @@
+package summergo
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestParseIconSizes(t *testing.T) {
+	sizes, scalable := parseIconSizes("16x16 32X32 invalid 0x0 48x24 any")
+	if !scalable {
+		t.Errorf("any should be scalable")
+	}
+	if len(sizes) != 3 || sizes[0] != 16 || sizes[1] != 32 || sizes[2] != 48 {
+		t.Errorf("Expected: %v, Got: %v", []int{16, 32, 48}, sizes)
+	}
+}
+
+func TestRankIcons(t *testing.T) {
+	candidates := []*iconCandidate{
+		{url: "mask", fallbackSize: 16, mask: true, scalable: true},
+		{url: "small", fallbackSize: 16},
+		{url: "multi", sizes: []int{16, 32, 96, 512}},
+		{url: "touch", fallbackSize: 180},
+		{url: "exact", sizes: []int{64}},
+	}
+
+	expected := []string{"exact", "multi", "touch", "small", "mask"}
+	ranked := rankIcons(candidates, 64)
+	for i, candidate := range ranked {
+		if candidate.url != expected[i] {
+			t.Errorf("%d: Expected: %s, Got: %s", i, expected[i], candidate.url)
+		}
+	}
+
+	// 小さく表示する場合は小さいものを優先する
+	if result := rankIcons(candidates, 16)[0].url; result != "small" {
+		t.Errorf("Expected: %s, Got: %s", "small", result)
+	}
+}
+
+const testIconPageHtml = `<html><head>
+	<title>Title</title>
+	<link rel="shortcut icon" href="/favicon-16.png">
+	<link rel="icon" type="text/html" href="/not-an-icon">
+	<link rel="mask-icon" href="/mask.svg" color="#000000">
+	<link rel="manifest" href="/app/manifest.json">
+</head><body></body></html>`
+
+const testManifestJson = `{
+	"icons": [
+		{"src": "icon-48.png", "sizes": "48x48", "type": "image/png"},
+		{"src": "icon-monochrome.png", "sizes": "64x64", "purpose": "monochrome"},
+		{"src": "icon-96.png", "sizes": "96x96", "type": "image/png"}
+	]
+}`
+
+func TestSummarizeHtmlSelectsIcon(t *testing.T) {
+	siteUrl, _ := url.Parse("https://example.com/")
+	s := NewSummarizer(
+		WithOEmbed(false),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/app/manifest.json" {
+				return newTestResponse(req, 200, "application/manifest+json", testManifestJson), nil
+			}
+			return newTestResponse(req, 404, "", ""), nil
+		})),
+	)
+
+	summary, err := s.SummarizeHtml(*siteUrl, strings.NewReader(testIconPageHtml), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Icon != "https://example.com/app/icon-96.png" {
+		t.Errorf("Expected: %s, Got: %s", "https://example.com/app/icon-96.png", summary.Icon)
+	}
+
+	// マニフェストを使わない場合はページのアイコンから選ぶ
+	s = NewSummarizer(WithOEmbed(false), WithManifest(false))
+	summary, err = s.SummarizeHtml(*siteUrl, strings.NewReader(testIconPageHtml), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Icon != "https://example.com/favicon-16.png" {
+		t.Errorf("Expected: %s, Got: %s", "https://example.com/favicon-16.png", summary.Icon)
+	}
+}
+
+func TestGetFaviconVerification(t *testing.T) {
+	docUrl, _ := url.Parse("https://example.com/")
+	idx := newEmptyMetaIndex()
+	idx.add("link", []html.Attribute{{Key: "rel", Val: "apple-touch-icon"}, {Key: "href", Val: "/missing.png"}}, "")
+	idx.add("link", []html.Attribute{{Key: "rel", Val: "icon"}, {Key: "href", Val: "/head-not-allowed.png"}}, "")
+
+	var requests []string
+	s := NewSummarizer(
+		WithIconVerification(true),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, req.Method+" "+req.URL.Path)
+			switch {
+			case req.URL.Path == "/head-not-allowed.png" && req.Method == "HEAD":
+				return newTestResponse(req, 405, "", ""), nil
+			case req.URL.Path == "/head-not-allowed.png":
+				return newTestResponse(req, 200, "image/png", ""), nil
+			}
+			return newTestResponse(req, 404, "text/html", ""), nil
+		})),
+	)
+
+	if result := s.getFavicon(context.Background(), idx, docUrl, docUrl, nil); result != "https://example.com/head-not-allowed.png" {
+		t.Errorf("Expected: %s, Got: %s", "https://example.com/head-not-allowed.png", result)
+	}
+
+	expected := []string{"HEAD /missing.png", "HEAD /head-not-allowed.png", "GET /head-not-allowed.png"}
+	if strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected: %v, Got: %v", expected, requests)
+	}
+
+	// Content-Typeがなくても取得できればよい
+	idx = newEmptyMetaIndex()
+	idx.add("link", []html.Attribute{{Key: "rel", Val: "icon"}, {Key: "href", Val: "/no-content-type.ico"}}, "")
+	s = NewSummarizer(
+		WithIconVerification(true),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/no-content-type.ico" {
+				return newTestResponse(req, 200, "", ""), nil
+			}
+			return newTestResponse(req, 200, "text/html; charset=utf-8", ""), nil
+		})),
+	)
+	if result := s.getFavicon(context.Background(), idx, docUrl, docUrl, nil); result != "https://example.com/no-content-type.ico" {
+		t.Errorf("Expected: %s, Got: %s", "https://example.com/no-content-type.ico", result)
+	}
+
+	// どれも取得できなければ空にする
+	if result := s.getFavicon(context.Background(), newEmptyMetaIndex(), docUrl, docUrl, nil); result != "" {
+		t.Errorf("Expected empty result, Got: %s", result)
+	}
+}
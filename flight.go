@@ -0,0 +1,81 @@
+package summergo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// 実行中の取得処理
+type flightCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	summary *Summary
+	err     error
+}
+
+// 同じURLへの同時リクエストを1回の取得にまとめる
+// 呼び出し元がキャンセルしても他の呼び出し元が待っている間は処理を続け、誰も待っていなくなったら中断する
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (*Summary, error)) (*Summary, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+
+	c, ok := g.calls[key]
+	if !ok {
+		// 共有する処理は呼び出し元のキャンセルを引き継がない
+		workCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &flightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = c
+
+		go func() {
+			defer func() {
+				// プラグインなどのpanicでプロセス全体を落とさないようにエラーとして返す
+				if r := recover(); r != nil {
+					c.summary, c.err = nil, fmt.Errorf("panic while summarizing: %v", r)
+				}
+				cancel()
+
+				g.mu.Lock()
+				if g.calls[key] == c {
+					delete(g.calls, key)
+				}
+				g.mu.Unlock()
+
+				close(c.done)
+			}()
+
+			c.summary, c.err = fn(workCtx)
+		}()
+	}
+	c.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		if c.err != nil {
+			return nil, c.err
+		}
+		// 呼び出し元ごとに別のインスタンスを返す
+		return c.summary.clone(), nil
+	case <-ctx.Done():
+		g.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			c.cancel()
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+
+		return nil, wrapRequestError(ctx.Err())
+	}
+}
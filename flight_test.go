@@ -0,0 +1,147 @@
+package summergo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSummarizerCoalescesRequests(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+
+	s := NewSummarizer(
+		WithOEmbed(false),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requests.Add(1)
+			<-release
+			return newTestResponse(req, 200, "text/html; charset=utf-8", testPageHtml), nil
+		})),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			summary, err := s.Summarize("https://example.com/viral")
+			if err != nil {
+				t.Errorf("failed to summarize: %v", err)
+			} else if summary.Title != "Test Page" {
+				t.Errorf("Expected: %s, Got: %s", "Test Page", summary.Title)
+			}
+		}()
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if requests.Load() != 1 {
+		t.Errorf("Expected: %d, Got: %d", 1, requests.Load())
+	}
+}
+
+func TestSummarizerCoalescedCancel(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	s := NewSummarizer(
+		WithOEmbed(false),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			close(started)
+			select {
+			case <-release:
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+			return newTestResponse(req, 200, "text/html; charset=utf-8", testPageHtml), nil
+		})),
+	)
+
+	// 片方がキャンセルしても、もう片方は結果を受け取れる
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error, 1)
+	go func() {
+		_, err := s.SummarizeContext(ctx, "https://example.com/")
+		canceled <- err
+	}()
+	<-started
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := s.SummarizeContext(context.Background(), "https://example.com/")
+		result <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-canceled; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected: %v, Got: %v", context.Canceled, err)
+	}
+
+	close(release)
+	if err := <-result; err != nil {
+		t.Errorf("shared fetch should not be aborted: %v", err)
+	}
+}
+
+func TestFlightGroupAbortsWithoutWaiters(t *testing.T) {
+	g := &flightGroup{}
+	aborted := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := g.do(ctx, "key", func(ctx context.Context) (*Summary, error) {
+		<-ctx.Done()
+		close(aborted)
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected: %v, Got: %v", context.Canceled, err)
+	}
+
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Errorf("shared work should be aborted when nobody waits")
+	}
+}
+
+type panicPlugin struct{}
+
+func (p *panicPlugin) Match(siteUrl *url.URL) bool {
+	return true
+}
+
+func (p *panicPlugin) Summarize(ctx context.Context, siteUrl *url.URL) (*Summary, error) {
+	panic("broken plugin")
+}
+
+func TestSummarizerRecoversPanic(t *testing.T) {
+	s := NewSummarizer(WithPlugins(&panicPlugin{}))
+
+	// panicしてもプロセスは落ちずにエラーになる
+	summary, err := s.Summarize("https://example.com/")
+	if err == nil || !strings.Contains(err.Error(), "broken plugin") {
+		t.Errorf("Expected panic error, Got: %v", err)
+	}
+	if summary != nil {
+		t.Errorf("Expected nil, Got: %+v", summary)
+	}
+
+	// 次の呼び出しは待たされない
+	if _, err := s.Summarize("https://example.com/"); err == nil {
+		t.Errorf("Expected panic error, Got: nil")
+	}
+}
@@ -1,6 +1,8 @@
 package summergo
 
 import (
+	"mime"
+
 	"golang.org/x/net/html"
 )
 
@@ -11,41 +13,156 @@ type findParam struct {
 	targetKey string
 }
 
+// インデックスに登録された要素
+type indexedElement struct {
+	// 文書内での出現順
+	pos   int
+	attrs []html.Attribute
+	text  string
+}
+
 // 属性の値の検索
-func getAttributeValue(node *html.Node, attrKey string) string {
-	for _, attr := range node.Attr {
-		if attr.Key == attrKey {
+func (e *indexedElement) attr(key string) string {
+	for _, attr := range e.attrs {
+		if attr.Key == key {
 			return attr.Val
 		}
 	}
 	return ""
 }
 
-func analyzeNode(node *html.Node, find ...*findParam) string {
+// 要約に使う要素は多くないので対象のタグだけインデックスする
+var indexedTags = map[string]bool{
+	"meta":  true,
+	"link":  true,
+	"title": true,
+	"base":  true,
+}
+
+// metaIndex indexes the metadata elements of a document so that every query is answered
+// without walking the tree again.
+type metaIndex struct {
+	count    int
+	titles   []*indexedElement
+	bases    []*indexedElement
+	links    []*indexedElement
+	elements map[string][]*indexedElement
+	// <script type="application/ld+json">の中身
+	jsonLd []string
+	// 全体をパースした場合の文書
+	doc *html.Node
+}
+
+func indexKey(tagName, attrKey, attrValue string) string {
+	return tagName + "\x00" + attrKey + "\x00" + attrValue
+}
+
+func newEmptyMetaIndex() *metaIndex {
+	return &metaIndex{elements: make(map[string][]*indexedElement)}
+}
+
+func newMetaIndex(doc *html.Node) *metaIndex {
+	idx := newEmptyMetaIndex()
+	idx.doc = doc
+
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		// SVGのtitleなどは対象外
+		if node.Type == html.ElementNode && node.Namespace == "" && indexedTags[node.Data] {
+			var text string
+			if node.Data == "title" && node.FirstChild != nil {
+				text = node.FirstChild.Data
+			}
+			idx.add(node.Data, node.Attr, text)
+		} else if node.Type == html.ElementNode && node.Namespace == "" && node.Data == "script" && isJsonLdScript(node.Attr) {
+			if node.FirstChild != nil {
+				idx.jsonLd = append(idx.jsonLd, node.FirstChild.Data)
+			}
+		}
+
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	return idx
+}
+
+func isJsonLdScript(attrs []html.Attribute) bool {
+	for _, attr := range attrs {
+		if attr.Key == "type" {
+			mediaType, _, err := mime.ParseMediaType(attr.Val)
+			return err == nil && mediaType == "application/ld+json"
+		}
+	}
+	return false
+}
+
+func (idx *metaIndex) add(tagName string, attrs []html.Attribute, text string) {
+	e := &indexedElement{pos: idx.count, attrs: attrs, text: text}
+	idx.count++
+
+	if tagName == "title" {
+		idx.titles = append(idx.titles, e)
+		return
+	} else if tagName == "base" {
+		idx.bases = append(idx.bases, e)
+		return
+	} else if tagName == "link" {
+		idx.links = append(idx.links, e)
+	}
+
+	for _, attr := range attrs {
+		key := indexKey(tagName, attr.Key, attr.Val)
+		idx.elements[key] = append(idx.elements[key], e)
+	}
+}
+
+// find returns the value of the first element in document order that matches any of find.
+// When a single element matches several params, the earlier param wins.
+func (idx *metaIndex) find(find ...*findParam) string {
+	result := ""
+	resultPos := -1
+
 	for _, f := range find {
+		var candidates []*indexedElement
 		if f.tagName == "title" {
-			if node.Type == html.ElementNode && node.Data == f.tagName {
-				return node.FirstChild.Data
+			candidates = idx.titles
+		} else {
+			candidates = idx.elements[indexKey(f.tagName, f.attrKey, f.attrValue)]
+		}
+
+		for _, e := range candidates {
+			var value string
+			if f.tagName == "title" {
+				value = e.text
 			} else {
+				value = e.attr(f.targetKey)
+			}
+
+			if value == "" {
 				continue
 			}
-		}
 
-		if node.Type == html.ElementNode && node.Data == f.tagName {
-			for _, attr := range node.Attr {
-				if attr.Key == f.attrKey && attr.Val == f.attrValue {
-					return getAttributeValue(node, f.targetKey)
-				}
+			if resultPos < 0 || e.pos < resultPos {
+				result = value
+				resultPos = e.pos
 			}
+			break
 		}
 	}
 
-	// 子ノードを再帰的に検索
-	for child := node.FirstChild; child != nil; child = child.NextSibling {
-		if result := analyzeNode(child, find...); result != "" {
-			return result
+	return result
+}
+
+// findAll returns every non-empty value of the elements matching find in document order.
+func (idx *metaIndex) findAll(find *findParam) []string {
+	var values []string
+	for _, e := range idx.elements[indexKey(find.tagName, find.attrKey, find.attrValue)] {
+		if value := e.attr(find.targetKey); value != "" {
+			values = append(values, value)
 		}
 	}
-
-	return ""
+	return values
 }
@@ -1,36 +1,200 @@
 package summergo
 
 import (
-	"golang.org/x/net/html"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"golang.org/x/net/html"
 )
 
-func TestAnalyzeNode(t *testing.T) {
+func TestMetaIndex(t *testing.T) {
 	// テスト用のHTMLノードを作成
-	htmlString := "<html><head><title>Test Title</title></head><body><div class=\"example\">Content</div></body></html>"
+	htmlString := `<html><head><title>Test Title</title><meta name="description" content="Description"><link rel="icon" href="/icon.png"></head><body><svg><title>SVG Title</title></svg></body></html>`
 	doc, err := html.Parse(strings.NewReader(htmlString))
 	if err != nil {
 		t.Fatal(err)
 	}
+	idx := newMetaIndex(doc)
 
 	// タイトル取得
-	result := analyzeNode(doc, &findParam{tagName: "title"})
+	result := idx.find(&findParam{tagName: "title"})
 	expected := "Test Title"
 	if result != expected {
 		t.Errorf("Expected: %s, Got: %s", expected, result)
 	}
 
 	// 属性を検索して取得する
-	result = analyzeNode(doc, &findParam{tagName: "div", attrKey: "class", attrValue: "example", targetKey: "class"})
-	expected = "example"
+	result = idx.find(&findParam{tagName: "link", attrKey: "rel", attrValue: "icon", targetKey: "href"})
+	expected = "/icon.png"
 	if result != expected {
 		t.Errorf("Expected: %s, Got: %s", expected, result)
 	}
 
 	// 存在しない要素
-	result = analyzeNode(doc, &findParam{tagName: "nonexistent"})
+	result = idx.find(&findParam{tagName: "nonexistent"})
 	if result != "" {
 		t.Errorf("Expected empty result, Got: %s", result)
 	}
 }
+
+func TestMetaIndexOrder(t *testing.T) {
+	// 文書内で先に出現した要素が優先される
+	htmlString := `<html><head>
+		<meta name="twitter:title" content="Twitter Title">
+		<meta property="og:title" content="">
+		<meta property="og:title" content="OG Title">
+		<title></title>
+	</head></html>`
+	doc, err := html.Parse(strings.NewReader(htmlString))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := newMetaIndex(doc)
+
+	result := getPageTitle(idx)
+	expected := "Twitter Title"
+	if result != expected {
+		t.Errorf("Expected: %s, Got: %s", expected, result)
+	}
+
+	// 空の値は無視する
+	result = idx.find(&findParam{tagName: "meta", attrKey: "property", attrValue: "og:title", targetKey: "content"})
+	expected = "OG Title"
+	if result != expected {
+		t.Errorf("Expected: %s, Got: %s", expected, result)
+	}
+}
+
+// 大きなページを模したHTMLを生成する
+func generateLargePage() string {
+	var b strings.Builder
+	b.WriteString(`<!DOCTYPE html><html><head><meta charset="utf-8"><title>Large Page</title>`)
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&b, `<meta name="custom-%d" content="value"><link rel="preload" href="/assets/%d.js" as="script">`, i, i)
+	}
+	b.WriteString(`<meta property="og:title" content="OG Title"><meta property="og:description" content="OG Description">`)
+	b.WriteString(`<meta property="og:image" content="/image.png"><link rel="icon" href="/favicon.ico"></head><body>`)
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&b, `<div class="item-%d"><p>Paragraph <a href="/link/%d">link</a> <span>text</span></p></div>`, i, i)
+	}
+	b.WriteString(`</body></html>`)
+	return b.String()
+}
+
+// 以前の実装: クエリごとに木全体を辿る
+func walkNode(node *html.Node, find ...*findParam) string {
+	for _, f := range find {
+		if node.Type == html.ElementNode && node.Data == f.tagName {
+			if f.tagName == "title" {
+				if node.FirstChild != nil {
+					return node.FirstChild.Data
+				}
+				continue
+			}
+
+			for _, attr := range node.Attr {
+				if attr.Key == f.attrKey && attr.Val == f.attrValue {
+					for _, a := range node.Attr {
+						if a.Key == f.targetKey {
+							return a.Val
+						}
+					}
+					return ""
+				}
+			}
+		}
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if result := walkNode(child, find...); result != "" {
+			return result
+		}
+	}
+
+	return ""
+}
+
+// SummarizeHtmlで行う検索
+var benchmarkQueries = [][]*findParam{
+	{{tagName: "meta", attrKey: "property", attrValue: "og:title", targetKey: "content"}, {tagName: "title"}},
+	{{tagName: "meta", attrKey: "property", attrValue: "og:description", targetKey: "content"}, {tagName: "meta", attrKey: "name", attrValue: "description", targetKey: "content"}},
+	{{tagName: "meta", attrKey: "property", attrValue: "og:image", targetKey: "content"}},
+	{{tagName: "link", attrKey: "type", attrValue: "application/json+oembed", targetKey: "href"}},
+	{{tagName: "meta", attrKey: "name", attrValue: "twitter:card", targetKey: "content"}},
+	{{tagName: "meta", attrKey: "name", attrValue: "twitter:player", targetKey: "content"}},
+	{{tagName: "meta", attrKey: "property", attrValue: "og:video", targetKey: "content"}},
+	{{tagName: "meta", attrKey: "name", attrValue: "twitter:player:width", targetKey: "content"}},
+	{{tagName: "meta", attrKey: "name", attrValue: "twitter:player:height", targetKey: "content"}},
+	{{tagName: "link", attrKey: "type", attrValue: "application/activity+json", targetKey: "href"}},
+	{{tagName: "meta", attrKey: "property", attrValue: "og:site_name", targetKey: "content"}},
+	{{tagName: "link", attrKey: "rel", attrValue: "icon", targetKey: "href"}},
+	{{tagName: "meta", attrKey: "property", attrValue: "mixi:content-rating", targetKey: "content"}},
+}
+
+// ベンチマークに使うページ
+// 生成したものに加えて、testdataに保存した実際のページを使う
+func benchmarkPages(b *testing.B) map[string]string {
+	pages := map[string]string{"generated": generateLargePage()}
+	for _, name := range []string{"go1.html", "rust-book-multithreaded.html"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			b.Fatal(err)
+		}
+		pages[name] = string(data)
+	}
+	return pages
+}
+
+func BenchmarkTreeWalk(b *testing.B) {
+	for name, page := range benchmarkPages(b) {
+		b.Run(name, func(b *testing.B) {
+			doc, err := html.Parse(strings.NewReader(page))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, query := range benchmarkQueries {
+					walkNode(doc, query...)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMetaIndex(b *testing.B) {
+	for name, page := range benchmarkPages(b) {
+		b.Run(name, func(b *testing.B) {
+			doc, err := html.Parse(strings.NewReader(page))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				idx := newMetaIndex(doc)
+				for _, query := range benchmarkQueries {
+					idx.find(query...)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSummarizeHtml(b *testing.B) {
+	page := generateLargePage()
+	siteUrl, _ := url.Parse("https://example.com/")
+	s := NewSummarizer(WithOEmbed(false))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
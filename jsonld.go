@@ -0,0 +1,295 @@
+package summergo
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// 入れ子が深すぎるものは打ち切る
+const maxStructuredDataDepth = 16
+
+var schemaOrgPrefixes = []string{
+	"http://schema.org/",
+	"https://schema.org/",
+	"schema:",
+}
+
+// schema.orgの型名やプロパティ名から接頭辞を取り除く
+func trimSchemaOrgPrefix(name string) string {
+	for _, prefix := range schemaOrgPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return name[len(prefix):]
+		}
+	}
+	return name
+}
+
+// Is reports whether the item has any of the given types.
+func (i *StructuredDataItem) Is(types ...string) bool {
+	for _, t := range i.Types {
+		if slices.Contains(types, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the first string value of the property.
+func (i *StructuredDataItem) String(name string) string {
+	for _, value := range i.Properties[name] {
+		if s, ok := value.(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// Item returns the first nested item of the property.
+func (i *StructuredDataItem) Item(name string) *StructuredDataItem {
+	for _, value := range i.Properties[name] {
+		if item, ok := value.(*StructuredDataItem); ok {
+			return item
+		}
+	}
+	return nil
+}
+
+func (i *StructuredDataItem) addValue(name string, value any) {
+	if i.Properties == nil {
+		i.Properties = make(map[string][]any)
+	}
+	i.Properties[name] = append(i.Properties[name], value)
+}
+
+// HTMLのコメントやCDATAで囲まれていることがある
+func trimJsonLdWrapper(text string) string {
+	text = strings.TrimSpace(text)
+	for _, wrapper := range [][2]string{{"<!--", "-->"}, {"//<![CDATA[", "//]]>"}, {"<![CDATA[", "]]>"}} {
+		if strings.HasPrefix(text, wrapper[0]) && strings.HasSuffix(text, wrapper[1]) {
+			text = strings.TrimSpace(text[len(wrapper[0]) : len(text)-len(wrapper[1])])
+		}
+	}
+	return text
+}
+
+// parseJsonLd parses the contents of <script type="application/ld+json"> blocks into items.
+// Top-level arrays and @graph are flattened; blocks that are not valid JSON are skipped.
+func parseJsonLd(blocks []string) []*StructuredDataItem {
+	var items []*StructuredDataItem
+
+	for _, block := range blocks {
+		decoder := json.NewDecoder(strings.NewReader(trimJsonLdWrapper(block)))
+		decoder.UseNumber()
+
+		var doc any
+		if err := decoder.Decode(&doc); err != nil {
+			continue
+		}
+
+		items = append(items, jsonLdTopLevelItems(doc, 0)...)
+	}
+
+	return items
+}
+
+func jsonLdTopLevelItems(doc any, depth int) []*StructuredDataItem {
+	if depth > maxStructuredDataDepth {
+		return nil
+	}
+
+	switch v := doc.(type) {
+	case []any:
+		var items []*StructuredDataItem
+		for _, child := range v {
+			items = append(items, jsonLdTopLevelItems(child, depth+1)...)
+		}
+		return items
+	case map[string]any:
+		if graph, ok := v["@graph"]; ok {
+			return jsonLdTopLevelItems(graph, depth+1)
+		}
+		return []*StructuredDataItem{jsonLdItem(v, depth)}
+	}
+
+	return nil
+}
+
+// JSONのオブジェクトをStructuredDataItemにする
+func jsonLdItem(obj map[string]any, depth int) *StructuredDataItem {
+	item := &StructuredDataItem{}
+
+	for key, value := range obj {
+		switch key {
+		case "@type":
+			for _, t := range jsonLdValues(value, depth) {
+				if s, ok := t.(string); ok {
+					item.Types = append(item.Types, trimSchemaOrgPrefix(s))
+				}
+			}
+		case "@id":
+			item.Id, _ = value.(string)
+		default:
+			// @contextなどのキーワードはプロパティではない
+			if strings.HasPrefix(key, "@") {
+				continue
+			}
+			name := trimSchemaOrgPrefix(key)
+			for _, v := range jsonLdValues(value, depth) {
+				item.addValue(name, v)
+			}
+		}
+	}
+
+	return item
+}
+
+// プロパティの値をstringと*StructuredDataItemの一覧にする
+func jsonLdValues(value any, depth int) []any {
+	if depth > maxStructuredDataDepth {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		return []any{v}
+	case json.Number:
+		return []any{v.String()}
+	case bool:
+		return []any{strconv.FormatBool(v)}
+	case []any:
+		var values []any
+		for _, child := range v {
+			values = append(values, jsonLdValues(child, depth+1)...)
+		}
+		return values
+	case map[string]any:
+		// {"@value": "..."}は値そのもの
+		if literal, ok := v["@value"]; ok {
+			return jsonLdValues(literal, depth+1)
+		}
+		if list, ok := v["@list"]; ok {
+			return jsonLdValues(list, depth+1)
+		}
+		return []any{jsonLdItem(v, depth+1)}
+	}
+
+	return nil
+}
+
+// サイトや組織を表す型で、ページの内容そのものではないもの
+var structuredDataContextTypes = []string{
+	"WebSite", "WebPage", "Organization", "NewsMediaOrganization", "Corporation", "Person",
+	"BreadcrumbList", "SiteNavigationElement", "WPHeader", "WPFooter", "WPSideBar", "ImageObject", "SearchAction",
+}
+
+// ページの主題を表すものを選ぶ
+// NewsArticleやProductなどがなければWebPageを使う
+func mainStructuredDataItem(items []*StructuredDataItem) *StructuredDataItem {
+	var page *StructuredDataItem
+	for _, item := range items {
+		if len(item.Types) == 0 {
+			continue
+		}
+		if !item.Is(structuredDataContextTypes...) {
+			return item
+		}
+		if page == nil && item.Is("WebPage") {
+			page = item
+		}
+	}
+	return page
+}
+
+// {"@id": "..."}だけの参照をトップレベルの同じ@idを持つものに置き換える
+func dereferenceStructuredData(items []*StructuredDataItem, item *StructuredDataItem) *StructuredDataItem {
+	if item == nil || item.Id == "" || len(item.Types) > 0 || len(item.Properties) > 0 {
+		return item
+	}
+
+	for _, candidate := range items {
+		if candidate.Id == item.Id {
+			return candidate
+		}
+	}
+	return item
+}
+
+// 画像を表す値のURL
+// 文字列の場合とImageObjectの場合がある
+func structuredDataImage(items []*StructuredDataItem, item *StructuredDataItem, name string) string {
+	for _, value := range item.Properties[name] {
+		switch v := value.(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case *StructuredDataItem:
+			v = dereferenceStructuredData(items, v)
+			if u := v.String("url"); u != "" {
+				return u
+			} else if u := v.String("contentUrl"); u != "" {
+				return u
+			}
+		}
+	}
+	return ""
+}
+
+// 構造化データから得られる要約の情報
+type structuredSummary struct {
+	title       string
+	description string
+	thumbnail   string
+	siteName    string
+}
+
+// 空の項目をotherで補う
+func (s *structuredSummary) fill(other structuredSummary) {
+	if s.title == "" {
+		s.title = other.title
+	}
+	if s.description == "" {
+		s.description = other.description
+	}
+	if s.thumbnail == "" {
+		s.thumbnail = other.thumbnail
+	}
+	if s.siteName == "" {
+		s.siteName = other.siteName
+	}
+}
+
+func summarizeStructuredData(items []*StructuredDataItem) structuredSummary {
+	var result structuredSummary
+
+	if main := mainStructuredDataItem(items); main != nil {
+		result.title = main.String("headline")
+		if result.title == "" {
+			result.title = main.String("name")
+		}
+		result.description = main.String("description")
+
+		result.thumbnail = structuredDataImage(items, main, "image")
+		if result.thumbnail == "" {
+			result.thumbnail = structuredDataImage(items, main, "thumbnailUrl")
+		}
+
+		if publisher := dereferenceStructuredData(items, main.Item("publisher")); publisher != nil {
+			result.siteName = publisher.String("name")
+		}
+	}
+
+	// 記事に発行元がなければサイトの名前を使う
+	for _, item := range items {
+		if result.siteName != "" {
+			break
+		}
+		if item.Is("WebSite") {
+			result.siteName = item.String("name")
+		}
+	}
+
+	return result
+}
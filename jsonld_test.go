@@ -0,0 +1,173 @@
+package summergo
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const testJsonLdGraph = `{
+	"@context": "https://schema.org",
+	"@graph": [
+		{"@type": "WebSite", "@id": "https://example.com/#website", "name": "Example Site"},
+		{"@type": "Organization", "@id": "https://example.com/#organization", "name": "Example Inc."},
+		{"@type": "ImageObject", "@id": "https://example.com/#primaryimage", "url": "https://example.com/primary.jpg"},
+		{"@type": "WebPage", "@id": "https://example.com/news/1", "name": "Page Name"},
+		{
+			"@type": ["NewsArticle"],
+			"headline": "Breaking News",
+			"description": {"@value": "Something happened"},
+			"image": {"@id": "https://example.com/#primaryimage"},
+			"publisher": {"@id": "https://example.com/#organization"},
+			"wordCount": 1200,
+			"isAccessibleForFree": true
+		}
+	]
+}`
+
+func TestParseJsonLd(t *testing.T) {
+	items := parseJsonLd([]string{testJsonLdGraph, "{invalid", `<!-- [{"@type": "http://schema.org/Product", "schema:name": "Gadget"}] -->`})
+	if len(items) != 6 {
+		t.Fatalf("Expected %d items, Got: %d", 6, len(items))
+	}
+
+	article := items[4]
+	if !article.Is("NewsArticle") {
+		t.Errorf("unexpected types: %v", article.Types)
+	}
+	if result := article.String("description"); result != "Something happened" {
+		t.Errorf("Expected: %s, Got: %s", "Something happened", result)
+	}
+	if result := article.String("wordCount"); result != "1200" {
+		t.Errorf("Expected: %s, Got: %s", "1200", result)
+	}
+	if result := article.String("isAccessibleForFree"); result != "true" {
+		t.Errorf("Expected: %s, Got: %s", "true", result)
+	}
+	if publisher := article.Item("publisher"); publisher == nil || publisher.Id != "https://example.com/#organization" {
+		t.Errorf("unexpected publisher: %+v", publisher)
+	}
+
+	// 接頭辞は取り除く
+	product := items[5]
+	if !product.Is("Product") || product.String("name") != "Gadget" {
+		t.Errorf("unexpected product: %+v", product)
+	}
+
+	// 参照を解決しても循環しないのでJSONにできる
+	if _, err := json.Marshal(items); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSummarizeStructuredData(t *testing.T) {
+	result := summarizeStructuredData(parseJsonLd([]string{testJsonLdGraph}))
+	expected := structuredSummary{
+		title:       "Breaking News",
+		description: "Something happened",
+		thumbnail:   "https://example.com/primary.jpg",
+		siteName:    "Example Inc.",
+	}
+	if result != expected {
+		t.Errorf("Expected: %+v, Got: %+v", expected, result)
+	}
+
+	// 発行元がなければWebSiteの名前を使う
+	result = summarizeStructuredData(parseJsonLd([]string{
+		`{"@type": "VideoObject", "name": "Video", "thumbnailUrl": ["/thumb.jpg"]}`,
+		`{"@type": "WebSite", "name": "Video Site"}`,
+	}))
+	expected = structuredSummary{title: "Video", thumbnail: "/thumb.jpg", siteName: "Video Site"}
+	if result != expected {
+		t.Errorf("Expected: %+v, Got: %+v", expected, result)
+	}
+
+	result = summarizeStructuredData(parseJsonLd([]string{
+		`{"@type": "Recipe", "name": "Curry", "image": [{"@type": "ImageObject", "contentUrl": "https://example.com/curry.jpg"}]}`,
+	}))
+	if result.title != "Curry" || result.thumbnail != "https://example.com/curry.jpg" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestSummarizeHtmlWithJsonLd(t *testing.T) {
+	siteUrl, _ := url.Parse("https://example.com/products/1")
+	s := NewSummarizer(WithOEmbed(false), WithManifest(false))
+
+	page := `<html><head>
+		<script type="application/ld+json">{"@type": "WebSite", "name": "Example Shop"}</script>
+	</head><body>
+		<h1>Gadget</h1>
+		<script type="application/ld+json; charset=utf-8">
+			{"@context": "https://schema.org", "@type": "Product", "name": "Gadget", "description": "A useful gadget", "image": "/gadget.png"}
+		</script>
+	</body></html>`
+
+	summary, err := s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Title != "Gadget" || summary.Description != "A useful gadget" || summary.SiteName != "Example Shop" {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if summary.Thumbnail != "https://example.com/gadget.png" {
+		t.Errorf("Expected: %s, Got: %s", "https://example.com/gadget.png", summary.Thumbnail)
+	}
+	if len(summary.StructuredData) != 2 {
+		t.Errorf("Expected %d items, Got: %d", 2, len(summary.StructuredData))
+	}
+
+	// metaタグがあればそちらを優先する
+	page = `<title>Title</title><meta name="description" content="Meta Description"><script type="application/ld+json">{"@type": "Article", "headline": "Headline", "description": "JSON-LD Description"}</script>`
+	summary, err = s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Title != "Title" || summary.Description != "Meta Description" {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if len(summary.StructuredData) != 1 || !summary.StructuredData[0].Is("Article") {
+		t.Errorf("JSON-LD in the head should be parsed: %+v", summary.StructuredData)
+	}
+}
+
+func TestSummarizeHtmlWithBodyJsonLd(t *testing.T) {
+	siteUrl, _ := url.Parse("https://example.com/news/1")
+	s := NewSummarizer(WithOEmbed(false), WithManifest(false))
+
+	jsonLd := `<script type="application/ld+json">{
+		"@type": "NewsArticle",
+		"image": "/news/1.jpg",
+		"author": {"@type": "Person", "name": "Alice"},
+		"datePublished": "2024-01-02T03:04:05Z"
+	}</script>`
+
+	// headに必要な情報が揃っていても本文のJSON-LDを使う
+	tests := []struct {
+		head      string
+		thumbnail string
+	}{
+		{head: `<title>News</title><meta name="description" content="Description">`, thumbnail: "https://example.com/news/1.jpg"},
+		{head: `<title>News</title><meta name="description" content="Description"><meta property="og:image" content="/og.jpg">`, thumbnail: "https://example.com/og.jpg"},
+	}
+
+	for _, test := range tests {
+		page := `<html><head>` + test.head + `</head><body><article><p>text</p>` + jsonLd + `</article></body></html>`
+		summary, err := s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if summary.Thumbnail != test.thumbnail {
+			t.Errorf("Expected: %s, Got: %s", test.thumbnail, summary.Thumbnail)
+		}
+		if summary.Article == nil || strings.Join(summary.Article.Authors, ",") != "Alice" || summary.Article.Published.IsZero() {
+			t.Errorf("unexpected article: %+v", summary.Article)
+		}
+		if len(summary.StructuredData) != 1 || !summary.StructuredData[0].Is("NewsArticle") {
+			t.Errorf("unexpected structured data: %+v", summary.StructuredData)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package summergo
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// 仕様では型が不正なメンバーは無視するので、まずはそのまま受け取る
+type rawManifest struct {
+	Name            json.RawMessage   `json:"name"`
+	ShortName       json.RawMessage   `json:"short_name"`
+	Description     json.RawMessage   `json:"description"`
+	StartUrl        json.RawMessage   `json:"start_url"`
+	Scope           json.RawMessage   `json:"scope"`
+	Display         json.RawMessage   `json:"display"`
+	ThemeColor      json.RawMessage   `json:"theme_color"`
+	BackgroundColor json.RawMessage   `json:"background_color"`
+	Icons           []json.RawMessage `json:"icons"`
+}
+
+type rawManifestIcon struct {
+	Src     json.RawMessage `json:"src"`
+	Sizes   json.RawMessage `json:"sizes"`
+	Type    json.RawMessage `json:"type"`
+	Purpose json.RawMessage `json:"purpose"`
+}
+
+// 文字列以外は空文字列として扱う
+func manifestString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return ""
+	}
+	return s
+}
+
+// parseManifest parses a Web App Manifest fetched from manifestUrl.
+// Members of the wrong type are ignored, and URLs are resolved against manifestUrl.
+func parseManifest(body io.Reader, manifestUrl *url.URL) (*Manifest, error) {
+	var raw rawManifest
+	if err := json.NewDecoder(body).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Url:             manifestUrl.String(),
+		Name:            manifestString(raw.Name),
+		ShortName:       manifestString(raw.ShortName),
+		Description:     manifestString(raw.Description),
+		StartUrl:        resolveUrl(manifestUrl, manifestString(raw.StartUrl)),
+		Scope:           resolveUrl(manifestUrl, manifestString(raw.Scope)),
+		Display:         manifestString(raw.Display),
+		ThemeColor:      manifestString(raw.ThemeColor),
+		BackgroundColor: manifestString(raw.BackgroundColor),
+	}
+
+	for _, rawIconJson := range raw.Icons {
+		var rawIcon rawManifestIcon
+		if err := json.Unmarshal(rawIconJson, &rawIcon); err != nil {
+			continue
+		}
+
+		src := resolveUrl(manifestUrl, manifestString(rawIcon.Src))
+		if src == "" {
+			continue
+		}
+
+		manifest.Icons = append(manifest.Icons, ManifestIcon{
+			Src:     src,
+			Sizes:   manifestString(rawIcon.Sizes),
+			Type:    manifestString(rawIcon.Type),
+			Purpose: manifestString(rawIcon.Purpose),
+		})
+	}
+
+	return manifest, nil
+}
+
+func getManifestUrl(idx *metaIndex) string {
+	return idx.find([]*findParam{
+		{tagName: "link", attrKey: "rel", attrValue: "manifest", targetKey: "href"},
+	}...)
+}
+
+// マニフェストを取得する
+// 取得や解析に失敗した場合はnilを返す
+func (s *Summarizer) fetchManifest(ctx context.Context, manifestUrl string) *Manifest {
+	if manifestUrl == "" {
+		return nil
+	}
+
+	req, newReqErr := http.NewRequestWithContext(ctx, "GET", manifestUrl, nil)
+	if newReqErr != nil {
+		return nil
+	}
+
+	req.Header.Set("User-Agent", s.userAgent)
+
+	resp, respErr := s.send(req)
+	if respErr != nil {
+		return nil
+	}
+
+	if resp.StatusCode != 200 {
+		_ = resp.Body.Close()
+		return nil
+	}
+
+	defer resp.Body.Close()
+
+	// リダイレクトされた場合は最終的なURLを基準にする
+	baseUrl := req.URL
+	if resp.Request != nil && resp.Request.URL != nil {
+		baseUrl = resp.Request.URL
+	}
+
+	manifest, err := parseManifest(resp.Body, baseUrl)
+	if err != nil {
+		return nil
+	}
+
+	return manifest
+}
+
+// マニフェストの名前
+// 短い名前のほうがカードに表示するのに向いている
+func (m *Manifest) siteName() string {
+	if m.ShortName != "" {
+		return m.ShortName
+	}
+	return m.Name
+}
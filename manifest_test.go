@@ -0,0 +1,109 @@
+package summergo
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParseManifest(t *testing.T) {
+	manifestUrl, _ := url.Parse("https://example.com/app/manifest.webmanifest")
+
+	manifest, err := parseManifest(strings.NewReader(`{
+		"name": "Example Application",
+		"short_name": 42,
+		"start_url": "../?source=pwa",
+		"display": "standalone",
+		"theme_color": "#336699",
+		"icons": [
+			{"src": "icons/192.png", "sizes": "192x192", "type": "image/png"},
+			{"src": 1},
+			"invalid",
+			{"src": "/icons/maskable.png", "purpose": "maskable"}
+		]
+	}`), manifestUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.Name != "Example Application" {
+		t.Errorf("Expected: %s, Got: %s", "Example Application", manifest.Name)
+	}
+	// 型が不正なメンバーは無視する
+	if manifest.ShortName != "" {
+		t.Errorf("Expected empty short_name, Got: %s", manifest.ShortName)
+	}
+	if manifest.StartUrl != "https://example.com/?source=pwa" {
+		t.Errorf("Expected: %s, Got: %s", "https://example.com/?source=pwa", manifest.StartUrl)
+	}
+	if manifest.ThemeColor != "#336699" || manifest.Display != "standalone" {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+
+	if len(manifest.Icons) != 2 {
+		t.Fatalf("Expected %d icons, Got: %d", 2, len(manifest.Icons))
+	}
+	if manifest.Icons[0].Src != "https://example.com/app/icons/192.png" || manifest.Icons[0].Sizes != "192x192" {
+		t.Errorf("unexpected icon: %+v", manifest.Icons[0])
+	}
+	if manifest.Icons[1].Src != "https://example.com/icons/maskable.png" || manifest.Icons[1].Purpose != "maskable" {
+		t.Errorf("unexpected icon: %+v", manifest.Icons[1])
+	}
+
+	if _, err := parseManifest(strings.NewReader("not json"), manifestUrl); err == nil {
+		t.Errorf("parse should be failed for invalid json")
+	}
+}
+
+func TestSummarizeHtmlWithManifest(t *testing.T) {
+	siteUrl, _ := url.Parse("https://example.com/")
+	s := NewSummarizer(
+		WithOEmbed(false),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/manifest.json" {
+				return newTestResponse(req, 200, "application/manifest+json", `{"name": "Example Application", "short_name": "Example", "theme_color": "navy", "icons": [{"src": "/icon.png", "sizes": "64x64"}]}`), nil
+			}
+			return newTestResponse(req, 404, "", ""), nil
+		})),
+	)
+
+	page := `<title>Title</title><link rel="manifest" href="/manifest.json">`
+	summary, err := s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Manifest == nil || summary.Manifest.Name != "Example Application" {
+		t.Fatalf("manifest should be fetched: %+v", summary.Manifest)
+	}
+	if summary.SiteName != "Example" {
+		t.Errorf("Expected: %s, Got: %s", "Example", summary.SiteName)
+	}
+	if summary.Icon != "https://example.com/icon.png" {
+		t.Errorf("Expected: %s, Got: %s", "https://example.com/icon.png", summary.Icon)
+	}
+	if summary.ThemeColor != "#000080" {
+		t.Errorf("Expected: %s, Got: %s", "#000080", summary.ThemeColor)
+	}
+
+	// og:site_nameがあればそちらを優先する
+	page = `<title>Title</title><meta property="og:site_name" content="OGP Name"><link rel="manifest" href="/manifest.json">`
+	summary, err = s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.SiteName != "OGP Name" {
+		t.Errorf("Expected: %s, Got: %s", "OGP Name", summary.SiteName)
+	}
+
+	// 取得できなければ無視する
+	page = `<title>Title</title><link rel="manifest" href="/missing.json">`
+	summary, err = s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Manifest != nil || summary.SiteName != "example.com" {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
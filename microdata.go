@@ -0,0 +1,233 @@
+package summergo
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+func getAttr(node *html.Node, key string) (string, bool) {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// 要素の中のテキストを空白を詰めて連結する
+func textContent(node *html.Node) string {
+	var b strings.Builder
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			return
+		} else if n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style") {
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(node)
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func forEachElement(node *html.Node, f func(n *html.Node)) {
+	if node.Type == html.ElementNode {
+		f(node)
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		forEachElement(child, f)
+	}
+}
+
+type microdataParser struct {
+	// itemrefで参照される要素
+	ids map[string]*html.Node
+}
+
+// extractMicrodata returns the top-level microdata items of doc, that is,
+// elements with itemscope that are not a property of another item.
+func extractMicrodata(doc *html.Node) []*StructuredDataItem {
+	p := &microdataParser{ids: make(map[string]*html.Node)}
+
+	var roots []*html.Node
+	forEachElement(doc, func(n *html.Node) {
+		if id, ok := getAttr(n, "id"); ok && id != "" {
+			if _, exists := p.ids[id]; !exists {
+				p.ids[id] = n
+			}
+		}
+
+		_, isItem := getAttr(n, "itemscope")
+		_, isProp := getAttr(n, "itemprop")
+		if isItem && !isProp {
+			roots = append(roots, n)
+		}
+	})
+
+	var items []*StructuredDataItem
+	for _, root := range roots {
+		items = append(items, p.item(root, 0))
+	}
+	return items
+}
+
+func (p *microdataParser) item(node *html.Node, depth int) *StructuredDataItem {
+	item := &StructuredDataItem{}
+
+	itemType, _ := getAttr(node, "itemtype")
+	for _, t := range strings.Fields(itemType) {
+		item.Types = append(item.Types, trimSchemaOrgPrefix(t))
+	}
+	item.Id, _ = getAttr(node, "itemid")
+
+	if depth > maxStructuredDataDepth {
+		return item
+	}
+
+	// 入れ子の要素の中は辿らない
+	visited := map[*html.Node]bool{node: true}
+	var crawl func(n *html.Node)
+	crawl = func(n *html.Node) {
+		if n.Type != html.ElementNode || visited[n] {
+			return
+		}
+		visited[n] = true
+
+		p.addProperty(item, n, depth)
+		if _, isItem := getAttr(n, "itemscope"); isItem {
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			crawl(child)
+		}
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		crawl(child)
+	}
+
+	itemRef, _ := getAttr(node, "itemref")
+	for _, id := range strings.Fields(itemRef) {
+		if ref, ok := p.ids[id]; ok {
+			crawl(ref)
+		}
+	}
+
+	return item
+}
+
+func (p *microdataParser) addProperty(item *StructuredDataItem, node *html.Node, depth int) {
+	itemProp, ok := getAttr(node, "itemprop")
+	if !ok {
+		return
+	}
+
+	var value any
+	if _, isItem := getAttr(node, "itemscope"); isItem {
+		value = p.item(node, depth+1)
+	} else {
+		value = microdataValue(node)
+	}
+
+	for _, name := range strings.Fields(itemProp) {
+		item.addValue(trimSchemaOrgPrefix(name), value)
+	}
+}
+
+// 要素の種類によって値を持つ属性が異なる
+func microdataValue(node *html.Node) string {
+	var key string
+	switch node.Data {
+	case "meta":
+		key = "content"
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		key = "src"
+	case "a", "area", "link":
+		key = "href"
+	case "object":
+		key = "data"
+	case "data", "meter":
+		key = "value"
+	case "time":
+		if datetime, ok := getAttr(node, "datetime"); ok {
+			return strings.TrimSpace(datetime)
+		}
+	}
+
+	if key != "" {
+		value, _ := getAttr(node, key)
+		return strings.TrimSpace(value)
+	}
+	return textContent(node)
+}
+
+// extractRdfa returns the items described with RDFa Lite attributes (typeof, property and resource).
+func extractRdfa(doc *html.Node) []*StructuredDataItem {
+	var items []*StructuredDataItem
+
+	var walk func(n *html.Node, current *StructuredDataItem, depth int)
+	walk = func(n *html.Node, current *StructuredDataItem, depth int) {
+		if depth > maxStructuredDataDepth {
+			return
+		}
+
+		if n.Type == html.ElementNode {
+			property, _ := getAttr(n, "property")
+			names := strings.Fields(property)
+
+			if typeOf, ok := getAttr(n, "typeof"); ok {
+				item := &StructuredDataItem{}
+				for _, t := range strings.Fields(typeOf) {
+					item.Types = append(item.Types, trimSchemaOrgPrefix(t))
+				}
+				item.Id, _ = getAttr(n, "resource")
+
+				if current != nil && len(names) > 0 {
+					for _, name := range names {
+						current.addValue(trimSchemaOrgPrefix(name), item)
+					}
+				} else {
+					items = append(items, item)
+				}
+
+				current = item
+				depth++
+			} else if current != nil {
+				// Open Graphのmetaタグなど、typeofの外にあるものは対象外
+				for _, name := range names {
+					current.addValue(trimSchemaOrgPrefix(name), rdfaValue(n))
+				}
+			}
+		}
+
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child, current, depth)
+		}
+	}
+	walk(doc, nil, 0)
+
+	return items
+}
+
+func rdfaValue(node *html.Node) string {
+	for _, key := range []string{"resource", "href", "src"} {
+		if value, ok := getAttr(node, key); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	if content, ok := getAttr(node, "content"); ok {
+		return content
+	}
+	if datetime, ok := getAttr(node, "datetime"); ok && node.Data == "time" {
+		return strings.TrimSpace(datetime)
+	}
+
+	return textContent(node)
+}
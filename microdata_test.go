@@ -0,0 +1,164 @@
+package summergo
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func parseTestHtml(t *testing.T, s string) *html.Node {
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestExtractMicrodata(t *testing.T) {
+	doc := parseTestHtml(t, `<html><body>
+		<div itemscope itemtype="https://schema.org/Product" itemref="price">
+			<h1 itemprop="name">  Gadget
+				<small>Pro</small></h1>
+			<img itemprop="image" src="/gadget.png">
+			<div itemprop="brand" itemscope itemtype="http://schema.org/Brand">
+				<span itemprop="name">Example</span>
+			</div>
+			<time itemprop="releaseDate" datetime="2024-01-02">January 2</time>
+			<a itemprop="url sameAs" href="https://example.com/gadget">link</a>
+		</div>
+		<p id="price"><meta itemprop="price" content="100"></p>
+		<div itemscope itemtype="https://schema.org/Person"><span itemprop="name">Alice</span></div>
+	</body></html>`)
+
+	items := extractMicrodata(doc)
+	if len(items) != 2 {
+		t.Fatalf("Expected %d items, Got: %d", 2, len(items))
+	}
+
+	product := items[0]
+	if !product.Is("Product") {
+		t.Errorf("unexpected types: %v", product.Types)
+	}
+
+	expected := map[string]string{
+		"name":        "Gadget Pro",
+		"image":       "/gadget.png",
+		"releaseDate": "2024-01-02",
+		"url":         "https://example.com/gadget",
+		"sameAs":      "https://example.com/gadget",
+		"price":       "100",
+	}
+	for name, value := range expected {
+		if result := product.String(name); result != value {
+			t.Errorf("%s: Expected: %s, Got: %s", name, value, result)
+		}
+	}
+
+	// 入れ子のプロパティは親の項目には含めない
+	brand := product.Item("brand")
+	if brand == nil || !brand.Is("Brand") || brand.String("name") != "Example" {
+		t.Errorf("unexpected brand: %+v", brand)
+	}
+
+	if result := items[1].String("name"); result != "Alice" {
+		t.Errorf("Expected: %s, Got: %s", "Alice", result)
+	}
+}
+
+func TestExtractRdfa(t *testing.T) {
+	doc := parseTestHtml(t, `<html prefix="og: https://ogp.me/ns#"><head>
+		<meta property="og:title" content="OG Title">
+	</head><body vocab="https://schema.org/">
+		<article typeof="BlogPosting" resource="https://example.com/posts/1">
+			<h1 property="headline">Post Title</h1>
+			<img property="image" src="/post.png">
+			<div property="author" typeof="Person"><span property="name">Bob</span></div>
+			<meta property="description" content="Post Description">
+		</article>
+	</body></html>`)
+
+	items := extractRdfa(doc)
+	if len(items) != 1 {
+		t.Fatalf("Expected %d items, Got: %d", 1, len(items))
+	}
+
+	post := items[0]
+	if !post.Is("BlogPosting") || post.Id != "https://example.com/posts/1" {
+		t.Errorf("unexpected item: %+v", post)
+	}
+	if post.String("headline") != "Post Title" || post.String("image") != "/post.png" || post.String("description") != "Post Description" {
+		t.Errorf("unexpected properties: %+v", post.Properties)
+	}
+	if author := post.Item("author"); author == nil || author.String("name") != "Bob" {
+		t.Errorf("unexpected author: %+v", author)
+	}
+	if _, ok := post.Properties["og:title"]; ok {
+		t.Errorf("Open Graph should not be included")
+	}
+}
+
+func TestSummarizeHtmlWithMicrodata(t *testing.T) {
+	siteUrl, _ := url.Parse("https://example.com/recipes/1")
+	s := NewSummarizer(WithOEmbed(false), WithManifest(false))
+
+	page := `<html><head></head><body>
+		<div itemscope itemtype="https://schema.org/Recipe">
+			<h1 itemprop="name">Curry</h1>
+			<p itemprop="description">A spicy curry</p>
+			<img itemprop="image" src="curry.jpg">
+		</div>
+	</body></html>`
+
+	summary, err := s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Title != "Curry" || summary.Description != "A spicy curry" {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if summary.Thumbnail != "https://example.com/recipes/curry.jpg" {
+		t.Errorf("Expected: %s, Got: %s", "https://example.com/recipes/curry.jpg", summary.Thumbnail)
+	}
+	if len(summary.StructuredData) != 1 || !summary.StructuredData[0].Is("Recipe") {
+		t.Errorf("unexpected structured data: %+v", summary.StructuredData)
+	}
+}
+
+func TestSummarizeHtmlWithMicrodataImage(t *testing.T) {
+	siteUrl, _ := url.Parse("https://example.com/products/1")
+	s := NewSummarizer(WithOEmbed(false), WithManifest(false))
+
+	// headにタイトルと説明があってもサムネイルがなければ本文から探す
+	page := `<html><head><title>Product</title><meta name="description" content="Description"></head><body>
+		<div itemscope itemtype="https://schema.org/Product">
+			<span itemprop="name">Product Name</span>
+			<img itemprop="image" src="product.jpg">
+		</div>
+	</body></html>`
+
+	summary, err := s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Title != "Product" || summary.Description != "Description" {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if summary.Thumbnail != "https://example.com/products/product.jpg" {
+		t.Errorf("Expected: %s, Got: %s", "https://example.com/products/product.jpg", summary.Thumbnail)
+	}
+
+	// metaタグで足りている場合は抽出しない
+	page = `<html><head><title>Product</title><meta name="description" content="Description"><meta property="og:image" content="/og.jpg"></head><body>
+		<div itemscope itemtype="https://schema.org/Product"><img itemprop="image" src="product.jpg"></div>
+	</body></html>`
+	summary, err = s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Thumbnail != "https://example.com/og.jpg" || len(summary.StructuredData) != 0 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
@@ -1,5 +1,7 @@
 package summergo
 
+import "time"
+
 type Player struct {
 	Url               string   `json:"url,omitempty"`
 	Width             int      `json:"width,omitempty"`
@@ -17,4 +19,58 @@ type Summary struct {
 	Player      Player `json:"player,omitempty"`
 	Sensitive   bool   `json:"sensitive"`
 	ActivityPub string `json:"activitypub,omitempty"`
+	// ThemeColor is the brand color of the site as "#rrggbb", or "#rrggbbaa" when it is translucent.
+	ThemeColor string `json:"themecolor,omitempty"`
+	// ThemeColorDark is the brand color the site uses in dark mode, if it declares one.
+	ThemeColorDark string `json:"themecolordark,omitempty"`
+	// Article is set when the page has authorship or publication metadata.
+	Article *Article `json:"article,omitempty"`
+	// StructuredData holds the schema.org items of the JSON-LD blocks found in the page.
+	// Microdata and RDFa Lite items are included only when they were extracted to fill in
+	// a title, description or thumbnail missing from the meta tags and JSON-LD.
+	StructuredData []*StructuredDataItem `json:"structureddata,omitempty"`
+	// Manifest is the Web App Manifest linked from the page, if any.
+	Manifest *Manifest `json:"manifest,omitempty"`
+	// PlayerError is set when the player of the page was rejected by validation.
+	PlayerError error `json:"-"`
+}
+
+// Article is the authorship and publication metadata of an article.
+type Article struct {
+	Authors   []string  `json:"authors,omitempty"`
+	Published time.Time `json:"published,omitzero"`
+	Modified  time.Time `json:"modified,omitzero"`
+	Section   string    `json:"section,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+}
+
+// ManifestIcon is an icon listed in a Web App Manifest.
+type ManifestIcon struct {
+	Src     string `json:"src"`
+	Sizes   string `json:"sizes,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
+}
+
+// Manifest is a Web App Manifest. URLs are absolute.
+type Manifest struct {
+	Url             string         `json:"url"`
+	Name            string         `json:"name,omitempty"`
+	ShortName       string         `json:"short_name,omitempty"`
+	Description     string         `json:"description,omitempty"`
+	StartUrl        string         `json:"start_url,omitempty"`
+	Scope           string         `json:"scope,omitempty"`
+	Display         string         `json:"display,omitempty"`
+	ThemeColor      string         `json:"theme_color,omitempty"`
+	BackgroundColor string         `json:"background_color,omitempty"`
+	Icons           []ManifestIcon `json:"icons,omitempty"`
+}
+
+// StructuredDataItem is a schema.org item embedded in a page.
+// Types and property names are given without the schema.org prefix.
+// Each property value is either a string or a nested *StructuredDataItem.
+type StructuredDataItem struct {
+	Types      []string         `json:"types,omitempty"`
+	Id         string           `json:"id,omitempty"`
+	Properties map[string][]any `json:"properties,omitempty"`
 }
@@ -1,36 +1,252 @@
 package summergo
 
 import (
-	"regexp"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
 )
 
+// 数値が文字列で返されることがあるので両方受け付ける
+type oembedInt int
+
+func (i *oembedInt) UnmarshalJSON(data []byte) error {
+	return i.UnmarshalText(bytes.Trim(data, `"`))
+}
+
+func (i *oembedInt) UnmarshalText(data []byte) error {
+	value := strings.TrimSpace(string(data))
+	if value == "" || value == "null" {
+		return nil
+	}
+
+	// "100%"のような値は無視する
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+
+	*i = oembedInt(n)
+	return nil
+}
+
+// oEmbed 1.0のレスポンス
+// JSONとXMLのどちらもこの構造体にデコードする
 type oembed struct {
-	Type   string `json:"type"`
-	Width  int    `json:"width"`
-	Height int    `json:"height"`
-	Html   string `json:"html"`
-}
-
-func getRequiredPermissionsFromIframe(iframe string) []string {
-	pattern := `allow\s*=\s*["']([^"']+?)["']`
-
-	// 正規表現にマッチする部分を検索
-	re := regexp.MustCompile(pattern)
-	matches := re.FindAllStringSubmatch(iframe, -1)
-
-	// 結果を取得
-	var allowList []string
-	for _, match := range matches {
-		// セミコロンで分割し、トリムしてから追加
-		attributes := strings.Split(match[1], ";")
-		for _, attr := range attributes {
-			trimmedAttr := strings.TrimSpace(attr)
-			if trimmedAttr != "" {
-				allowList = append(allowList, trimmedAttr)
-			}
+	Type            string    `json:"type" xml:"type"`
+	Version         string    `json:"version" xml:"version"`
+	Title           string    `json:"title" xml:"title"`
+	AuthorName      string    `json:"author_name" xml:"author_name"`
+	AuthorUrl       string    `json:"author_url" xml:"author_url"`
+	ProviderName    string    `json:"provider_name" xml:"provider_name"`
+	ProviderUrl     string    `json:"provider_url" xml:"provider_url"`
+	CacheAge        oembedInt `json:"cache_age" xml:"cache_age"`
+	ThumbnailUrl    string    `json:"thumbnail_url" xml:"thumbnail_url"`
+	ThumbnailWidth  oembedInt `json:"thumbnail_width" xml:"thumbnail_width"`
+	ThumbnailHeight oembedInt `json:"thumbnail_height" xml:"thumbnail_height"`
+	// photoの場合は画像のURL
+	Url    string    `json:"url" xml:"url"`
+	Width  oembedInt `json:"width" xml:"width"`
+	Height oembedInt `json:"height" xml:"height"`
+	Html   string    `json:"html" xml:"html"`
+}
+
+// JSONかXMLかを中身から判断してデコードする
+func decodeOEmbed(body []byte) (*oembed, error) {
+	embed := &oembed{}
+	trimmed := bytes.TrimSpace(body)
+
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		decoder := xml.NewDecoder(bytes.NewReader(trimmed))
+		decoder.CharsetReader = charset.NewReaderLabel
+		if err := decoder.Decode(embed); err != nil {
+			return nil, err
 		}
+		return embed, nil
+	}
+
+	if err := json.Unmarshal(trimmed, embed); err != nil {
+		return nil, err
+	}
+	return embed, nil
+}
+
+// サムネイルとして使える画像
+func (o *oembed) thumbnail() string {
+	if o.ThumbnailUrl != "" {
+		return o.ThumbnailUrl
+	} else if o.Type == "photo" {
+		return o.Url
+	}
+	return ""
+}
+
+// ページを取得しなくても要約を作れるか
+func (o *oembed) complete() bool {
+	if o.Title == "" || o.thumbnail() == "" {
+		return false
+	}
+
+	// videoとrichはプレイヤーにするiframeが必要
+	if o.Type == "video" || o.Type == "rich" {
+		return parseOEmbedIframe(o.Html) != nil
+	}
+	return o.Type == "photo" || o.Type == "link"
+}
+
+// cache_ageをキャッシュのTTLに使う
+func (o *oembed) cacheHeader() http.Header {
+	header := http.Header{}
+	if o.CacheAge > 0 {
+		header.Set("Cache-Control", "max-age="+strconv.Itoa(int(o.CacheAge)))
+	}
+	return header
+}
+
+func (s *Summarizer) summaryFromOEmbed(ctx context.Context, siteUrl *url.URL, embed *oembed) *Summary {
+	siteName := embed.ProviderName
+	if siteName == "" {
+		siteName = siteUrl.Host
+	}
+
+	idx := newEmptyMetaIndex()
+	summary := &Summary{
+		Url:       siteUrl.String(),
+		Title:     embed.Title,
+		Thumbnail: resolveUrl(siteUrl, embed.thumbnail()),
+		SiteName:  siteName,
+		Icon:      s.getFavicon(ctx, idx, siteUrl, siteUrl, nil),
+	}
+
+	if embed.Html != "" {
+		player, requested := getPlayerFromOEmbed(embed, idx)
+		summary.PlayerError = s.preparePlayer(player, requested)
+		summary.Player = *player
+	}
+
+	return summary
+}
+
+// oEmbedを取得する
+func (s *Summarizer) fetchOEmbed(ctx context.Context, oembedUrl string) *oembed {
+	if oembedUrl == "" {
+		return nil
+	}
+
+	req, newReqErr := http.NewRequestWithContext(ctx, "GET", oembedUrl, nil)
+	if newReqErr != nil {
+		return nil
+	}
+
+	req.Header.Set("User-Agent", s.userAgent)
+
+	resp, respErr := s.send(req)
+	if respErr != nil {
+		return nil
+	}
+
+	if resp.StatusCode != 200 {
+		_ = resp.Body.Close()
+		return nil
 	}
 
-	return allowList
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil
+	}
+
+	embed, err := decodeOEmbed(body)
+	if err != nil {
+		return nil
+	}
+
+	return embed
+}
+
+// oEmbedのhtmlに含まれるiframe
+type oembedIframe struct {
+	src             string
+	width           int
+	height          int
+	allow           []string
+	allowFullscreen bool
+}
+
+// 必要な権限の一覧
+// allowfullscreen属性はallow="fullscreen"と同じ
+func (f *oembedIframe) permissions() []string {
+	permissions := slices.Clone(f.allow)
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	if f.allowFullscreen && !slices.Contains(permissions, "fullscreen") {
+		permissions = append(permissions, "fullscreen")
+	}
+	return permissions
+}
+
+// parseOEmbedIframe extracts the iframe from the html of an oEmbed response.
+// It returns nil unless the html consists of a single iframe whose src is an https URL.
+func parseOEmbedIframe(src string) *oembedIframe {
+	var iframe *oembedIframe
+	z := html.NewTokenizer(strings.NewReader(src))
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() != io.EOF || iframe == nil {
+				return nil
+			}
+
+			u, err := url.Parse(iframe.src)
+			if err != nil || u.Scheme != "https" || u.Host == "" {
+				return nil
+			}
+			return iframe
+		case html.TextToken:
+			// iframe以外の内容があるものは受け付けない
+			if strings.TrimSpace(string(z.Text())) != "" {
+				return nil
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) != "iframe" {
+				return nil
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := z.Token()
+			if token.Data != "iframe" || iframe != nil {
+				return nil
+			}
+
+			iframe = &oembedIframe{}
+			for _, attr := range token.Attr {
+				switch attr.Key {
+				case "src":
+					iframe.src = strings.TrimSpace(attr.Val)
+				case "width":
+					iframe.width, _ = strconv.Atoi(strings.TrimSpace(attr.Val))
+				case "height":
+					iframe.height, _ = strconv.Atoi(strings.TrimSpace(attr.Val))
+				case "allow":
+					iframe.allow = parseAllowAttribute(attr.Val)
+				case "allowfullscreen":
+					iframe.allowFullscreen = true
+				}
+			}
+		case html.CommentToken, html.DoctypeToken:
+			return nil
+		}
+	}
 }
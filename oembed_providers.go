@@ -0,0 +1,132 @@
+package summergo
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// oembed.comのproviders.jsonから主要なものを抜粋したもの
+//
+//go:embed oembed_providers.json
+var defaultOEmbedProvidersJson []byte
+
+// OEmbedProvider is an oEmbed provider in the format of https://oembed.com/providers.json.
+type OEmbedProvider struct {
+	ProviderName string           `json:"provider_name"`
+	ProviderUrl  string           `json:"provider_url"`
+	Endpoints    []OEmbedEndpoint `json:"endpoints"`
+}
+
+// OEmbedEndpoint is an oEmbed API endpoint and the URL schemes it serves.
+type OEmbedEndpoint struct {
+	Schemes   []string `json:"schemes,omitempty"`
+	Url       string   `json:"url"`
+	Discovery bool     `json:"discovery,omitempty"`
+	Formats   []string `json:"formats,omitempty"`
+}
+
+// LoadOEmbedProviders reads a provider list in the providers.json format.
+func LoadOEmbedProviders(r io.Reader) ([]OEmbedProvider, error) {
+	var providers []OEmbedProvider
+	if err := json.NewDecoder(r).Decode(&providers); err != nil {
+		return nil, err
+	}
+	return providers, nil
+}
+
+// DefaultOEmbedProviders returns the built-in provider list.
+func DefaultOEmbedProviders() []OEmbedProvider {
+	providers, err := LoadOEmbedProviders(bytes.NewReader(defaultOEmbedProvidersJson))
+	if err != nil {
+		panic(err)
+	}
+	return providers
+}
+
+// URLのパターンを正規表現にしたエンドポイント
+type oembedEndpointMatcher struct {
+	schemes []*regexp.Regexp
+	url     string
+	// XMLしか対応していないエンドポイント
+	xmlOnly bool
+}
+
+// "https://*.youtube.com/watch*"のようなパターンを正規表現に変換する
+func compileOEmbedScheme(scheme string) (*regexp.Regexp, error) {
+	pattern := strings.ReplaceAll(regexp.QuoteMeta(scheme), `\*`, `.*`)
+	return regexp.Compile("^" + pattern + "$")
+}
+
+func compileOEmbedProviders(providers []OEmbedProvider) []*oembedEndpointMatcher {
+	var matchers []*oembedEndpointMatcher
+	for _, provider := range providers {
+		for _, endpoint := range provider.Endpoints {
+			if len(endpoint.Schemes) == 0 || endpoint.Url == "" {
+				continue
+			}
+
+			matcher := &oembedEndpointMatcher{url: endpoint.Url}
+			if len(endpoint.Formats) > 0 {
+				matcher.xmlOnly = true
+				for _, format := range endpoint.Formats {
+					if format == "json" {
+						matcher.xmlOnly = false
+					}
+				}
+			}
+
+			for _, scheme := range endpoint.Schemes {
+				// 不正なパターンは無視する
+				if re, err := compileOEmbedScheme(scheme); err == nil {
+					matcher.schemes = append(matcher.schemes, re)
+				}
+			}
+
+			if len(matcher.schemes) > 0 {
+				matchers = append(matchers, matcher)
+			}
+		}
+	}
+
+	return matchers
+}
+
+var defaultOEmbedMatchers = sync.OnceValue(func() []*oembedEndpointMatcher {
+	return compileOEmbedProviders(DefaultOEmbedProviders())
+})
+
+// siteUrlに対応するoEmbedのURLを返す
+func findOEmbedEndpoint(matchers []*oembedEndpointMatcher, siteUrl string) string {
+	for _, matcher := range matchers {
+		for _, scheme := range matcher.schemes {
+			if !scheme.MatchString(siteUrl) {
+				continue
+			}
+
+			format := "json"
+			if matcher.xmlOnly {
+				format = "xml"
+			}
+
+			endpoint, err := url.Parse(strings.ReplaceAll(matcher.url, "{format}", format))
+			if err != nil {
+				return ""
+			}
+
+			query := endpoint.Query()
+			query.Set("url", siteUrl)
+			query.Set("format", format)
+			endpoint.RawQuery = query.Encode()
+
+			return endpoint.String()
+		}
+	}
+
+	return ""
+}
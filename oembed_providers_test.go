@@ -0,0 +1,95 @@
+package summergo
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFindOEmbedEndpoint(t *testing.T) {
+	matchers := defaultOEmbedMatchers()
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "https://www.youtube.com/watch?v=zK-RUYiYLok", expected: "https://www.youtube.com/oembed?format=json&url=https%3A%2F%2Fwww.youtube.com%2Fwatch%3Fv%3DzK-RUYiYLok"},
+		{url: "https://vimeo.com/76979871", expected: "https://vimeo.com/api/oembed.json?format=json&url=https%3A%2F%2Fvimeo.com%2F76979871"},
+		{url: "https://example.com/", expected: ""},
+	}
+
+	for _, test := range tests {
+		if result := findOEmbedEndpoint(matchers, test.url); result != test.expected {
+			t.Errorf("Expected: %s, Got: %s", test.expected, result)
+		}
+	}
+}
+
+func TestLoadOEmbedProviders(t *testing.T) {
+	providers, err := LoadOEmbedProviders(strings.NewReader(`[{
+		"provider_name": "Example",
+		"provider_url": "https://example.com/",
+		"endpoints": [{"schemes": ["https://example.com/photos/*"], "url": "https://example.com/oembed", "formats": ["xml"]}]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matchers := compileOEmbedProviders(providers)
+	result := findOEmbedEndpoint(matchers, "https://example.com/photos/1")
+	expected := "https://example.com/oembed?format=xml&url=https%3A%2F%2Fexample.com%2Fphotos%2F1"
+	if result != expected {
+		t.Errorf("Expected: %s, Got: %s", expected, result)
+	}
+
+	if len(DefaultOEmbedProviders()) == 0 {
+		t.Errorf("built-in providers should not be empty")
+	}
+}
+
+func TestSummarizeWithOEmbedProvider(t *testing.T) {
+	providers, err := LoadOEmbedProviders(strings.NewReader(`[{
+		"provider_name": "Example Photos",
+		"endpoints": [{"schemes": ["https://photos.example.com/*"], "url": "https://example.com/oembed"}]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pageFetched := false
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/oembed" {
+			return newTestResponse(req, 200, "application/json", `{"type": "photo", "title": "Photo", "provider_name": "Example Photos", "url": "https://photos.example.com/1.jpg", "cache_age": 60}`), nil
+		}
+
+		pageFetched = true
+		return newTestResponse(req, 200, "text/html; charset=utf-8", `<html><head><title>Page</title></head></html>`), nil
+	})
+
+	// ページにoEmbedのリンクがなくてもプロバイダーから取得する
+	s := NewSummarizer(WithOEmbedProviders(providers), WithTransport(transport))
+	summary, err := s.Summarize("https://photos.example.com/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Thumbnail != "https://photos.example.com/1.jpg" {
+		t.Errorf("Expected: %s, Got: %s", "https://photos.example.com/1.jpg", summary.Thumbnail)
+	}
+	if !pageFetched {
+		t.Errorf("page should be fetched without the shortcut")
+	}
+
+	// oEmbedだけで十分ならページを取得しない
+	pageFetched = false
+	s = NewSummarizer(WithOEmbedProviders(providers), WithOEmbedShortcut(true), WithTransport(transport))
+	summary, err = s.Summarize("https://photos.example.com/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Title != "Photo" || summary.SiteName != "Example Photos" {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if pageFetched {
+		t.Errorf("page should not be fetched with the shortcut")
+	}
+}
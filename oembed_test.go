@@ -1,6 +1,13 @@
 package summergo
 
-import "testing"
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
 
 func containsString(arr []string, target string) bool {
 	for _, element := range arr {
@@ -11,14 +18,201 @@ func containsString(arr []string, target string) bool {
 	return false
 }
 
-func TestGetRequiredPermissionsFromIframe(t *testing.T) {
+func TestParseOEmbedIframe(t *testing.T) {
 	testIframe := "<iframe width=\"200\" height=\"113\" src=\"https://www.youtube.com/embed/zK-RUYiYLok?feature=oembed\" frameborder=\"0\" allow=\"accelerometer; autoplay; clipboard-write; encrypted-media; gyroscope; picture-in-picture; web-share\" allowfullscreen title=\"【崩壊：スターレイル】EP「制御不能」\"></iframe>"
 
-	permissions := getRequiredPermissionsFromIframe(testIframe)
+	iframe := parseOEmbedIframe(testIframe)
+	if iframe == nil {
+		t.Fatal("iframe should be parsed")
+	}
 
+	permissions := iframe.permissions()
 	if !containsString(permissions, "autoplay") {
 		t.Errorf("autoplay should be contained")
 	} else if !containsString(permissions, "picture-in-picture") {
 		t.Errorf("picture-in-picture should be contained")
+	} else if !containsString(permissions, "fullscreen") {
+		t.Errorf("fullscreen should be contained by allowfullscreen")
+	}
+
+	if iframe.src != "https://www.youtube.com/embed/zK-RUYiYLok?feature=oembed" {
+		t.Errorf("Expected: %s, Got: %s", "https://www.youtube.com/embed/zK-RUYiYLok?feature=oembed", iframe.src)
+	}
+	if iframe.width != 200 || iframe.height != 113 {
+		t.Errorf("unexpected size: %dx%d", iframe.width, iframe.height)
+	}
+}
+
+func TestParseOEmbedIframeRejects(t *testing.T) {
+	rejected := []string{
+		"",
+		`<iframe src="http://example.com/embed"></iframe>`,
+		`<iframe src="javascript:alert(1)"></iframe>`,
+		`<iframe></iframe>`,
+		`<iframe src="https://example.com/1"></iframe><iframe src="https://example.com/2"></iframe>`,
+		`<blockquote class="twitter-tweet"><a href="https://twitter.com/"></a></blockquote><script async src="https://platform.twitter.com/widgets.js"></script>`,
+		`<iframe src="https://example.com/embed"></iframe><script>alert(1)</script>`,
+		`text <iframe src="https://example.com/embed"></iframe>`,
+	}
+
+	for _, src := range rejected {
+		if iframe := parseOEmbedIframe(src); iframe != nil {
+			t.Errorf("should be rejected: %s", src)
+		}
+	}
+}
+
+func TestOEmbedDecode(t *testing.T) {
+	body := `{
+		"type": "video",
+		"version": "1.0",
+		"title": "Video Title",
+		"author_name": "Author",
+		"author_url": "https://example.com/author",
+		"provider_name": "Example Video",
+		"provider_url": "https://example.com/",
+		"cache_age": "3600",
+		"thumbnail_url": "https://example.com/thumbnail.jpg",
+		"thumbnail_width": 480,
+		"thumbnail_height": "360",
+		"width": 640,
+		"height": null,
+		"html": "<iframe src=\"https://example.com/embed\"></iframe>"
+	}`
+
+	embed := &oembed{}
+	if err := json.Unmarshal([]byte(body), embed); err != nil {
+		t.Fatal(err)
+	}
+
+	if embed.Title != "Video Title" || embed.AuthorName != "Author" || embed.ProviderName != "Example Video" {
+		t.Errorf("unexpected oEmbed: %+v", embed)
+	}
+	if embed.CacheAge != 3600 || embed.ThumbnailWidth != 480 || embed.ThumbnailHeight != 360 || embed.Width != 640 || embed.Height != 0 {
+		t.Errorf("unexpected oEmbed: %+v", embed)
+	}
+
+	// photoはurlをサムネイルにする
+	photo := &oembed{Type: "photo", Url: "https://example.com/photo.jpg"}
+	if photo.thumbnail() != "https://example.com/photo.jpg" {
+		t.Errorf("Expected: %s, Got: %s", "https://example.com/photo.jpg", photo.thumbnail())
+	}
+}
+
+func TestSummarizeWithOEmbed(t *testing.T) {
+	s := NewSummarizer(WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/oembed" {
+			return newTestResponse(req, 200, "application/json", `{
+				"type": "video",
+				"title": "OEmbed Title",
+				"provider_name": "Example Video",
+				"thumbnail_url": "https://example.com/thumbnail.jpg",
+				"width": 640,
+				"height": 360,
+				"html": "<iframe src=\"https://example.com/embed\" allow=\"autoplay; gyroscope\"></iframe>"
+			}`), nil
+		}
+
+		return newTestResponse(req, 200, "text/html; charset=utf-8", `<html><head>
+			<link type="application/json+oembed" href="https://example.com/oembed">
+			<meta name="twitter:player" content="https://example.com/embed">
+		</head></html>`), nil
+	})))
+
+	summary, err := s.Summarize("https://example.com/watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Title != "OEmbed Title" {
+		t.Errorf("Expected: %s, Got: %s", "OEmbed Title", summary.Title)
+	}
+	if summary.Thumbnail != "https://example.com/thumbnail.jpg" {
+		t.Errorf("Expected: %s, Got: %s", "https://example.com/thumbnail.jpg", summary.Thumbnail)
+	}
+	if summary.SiteName != "Example Video" {
+		t.Errorf("Expected: %s, Got: %s", "Example Video", summary.SiteName)
+	}
+	if summary.Player.Width != 640 || summary.Player.Height != 360 {
+		t.Errorf("unexpected player: %+v", summary.Player)
+	}
+	if !containsString(summary.Player.IframePermissions, "autoplay") || containsString(summary.Player.IframePermissions, "gyroscope") {
+		t.Errorf("unexpected permissions: %v", summary.Player.IframePermissions)
+	}
+}
+
+func TestOEmbedDecodeXml(t *testing.T) {
+	body := `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<oembed>
+	<type>video</type>
+	<version>1.0</version>
+	<title>XML Title</title>
+	<provider_name>Example</provider_name>
+	<thumbnail_url>https://example.com/thumbnail.jpg</thumbnail_url>
+	<width>640</width>
+	<height>360</height>
+	<html>&lt;iframe src="https://example.com/embed" allow="autoplay"&gt;&lt;/iframe&gt;</html>
+</oembed>`
+
+	embed, err := decodeOEmbed([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if embed.Type != "video" || embed.Title != "XML Title" || embed.ProviderName != "Example" || embed.ThumbnailUrl != "https://example.com/thumbnail.jpg" {
+		t.Errorf("unexpected oEmbed: %+v", embed)
+	}
+	if embed.Width != 640 || embed.Height != 360 {
+		t.Errorf("unexpected oEmbed: %+v", embed)
+	}
+	if iframe := parseOEmbedIframe(embed.Html); iframe == nil || !containsString(iframe.allow, "autoplay") {
+		t.Errorf("html should be unescaped: %s", embed.Html)
+	}
+}
+
+func TestGetOEmbedUrl(t *testing.T) {
+	tests := []struct {
+		head     string
+		expected string
+	}{
+		{head: `<link type="text/xml+oembed" href="https://example.com/oembed.xml">`, expected: "https://example.com/oembed.xml"},
+		{head: `<link type="text/xml+oembed" href="https://example.com/oembed.xml"><link type="application/json+oembed" href="https://example.com/oembed.json">`, expected: "https://example.com/oembed.json"},
+		{head: `<title>No oEmbed</title>`, expected: ""},
+	}
+
+	for _, test := range tests {
+		idx, err := scanHead(html.NewTokenizer(strings.NewReader("<html><head>" + test.head + "</head></html>")))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result := getOEmbedUrl(idx); result != test.expected {
+			t.Errorf("Expected: %s, Got: %s", test.expected, result)
+		}
+	}
+}
+
+func TestPlayerFromOEmbedIframe(t *testing.T) {
+	// ページにプレイヤーの指定がなければiframeのsrcを使う
+	embed := &oembed{Type: "video", Html: `<iframe src="https://example.com/embed/1" width="480" height="270" allow="autoplay"></iframe>`}
+	player, requested := getPlayerFromOEmbed(embed, newEmptyMetaIndex())
+	if player.Url != "https://example.com/embed/1" {
+		t.Errorf("Expected: %s, Got: %s", "https://example.com/embed/1", player.Url)
+	}
+	if player.Width != 480 || player.Height != 270 {
+		t.Errorf("unexpected size: %dx%d", player.Width, player.Height)
+	}
+	if len(requested) != 1 || requested[0] != "autoplay" {
+		t.Errorf("unexpected permissions: %v", requested)
+	}
+
+	// ページの指定を優先する
+	idx, err := scanHead(html.NewTokenizer(strings.NewReader(`<meta property="og:video" content="https://example.com/video/1">`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	player, _ = getPlayerFromOEmbed(embed, idx)
+	if player.Url != "https://example.com/video/1" {
+		t.Errorf("Expected: %s, Got: %s", "https://example.com/video/1", player.Url)
 	}
 }
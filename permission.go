@@ -0,0 +1,91 @@
+package summergo
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// PermissionPolicy decides which iframe permissions are granted to players.
+type PermissionPolicy struct {
+	// Allowed is the list of features granted to players of any host.
+	Allowed []string
+	// Hosts overrides Allowed for players of the given hosts and their subdomains.
+	Hosts map[string][]string
+}
+
+// DefaultPermissionPolicy returns the policy used when none is configured.
+func DefaultPermissionPolicy() *PermissionPolicy {
+	return &PermissionPolicy{
+		Allowed: []string{"autoplay", "clipboard-write", "picture-in-picture", "web-share", "fullscreen"},
+	}
+}
+
+// parseAllowAttribute parses the allow attribute of an iframe and returns the requested features.
+// Origin lists such as "autoplay 'self'" are dropped, and features with the 'none' allowlist are omitted.
+func parseAllowAttribute(allow string) []string {
+	features := []string{}
+	for _, directive := range strings.Split(allow, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+
+		feature := strings.ToLower(fields[0])
+		if len(fields) == 2 && strings.EqualFold(fields[1], "'none'") {
+			continue
+		}
+
+		if !slices.Contains(features, feature) {
+			features = append(features, feature)
+		}
+	}
+
+	return features
+}
+
+// ホストに適用される許可リストを返す
+// サブドメインは親ドメインの設定を引き継ぐ
+func (p *PermissionPolicy) allowedFor(host string) []string {
+	host = strings.ToLower(host)
+	for host != "" {
+		if allowed, ok := p.Hosts[host]; ok {
+			return allowed
+		}
+
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			break
+		}
+		host = parent
+	}
+
+	return p.Allowed
+}
+
+// grant returns the permissions granted to the player at playerUrl.
+// requested is the list of features the player asks for; nil means the player did not declare it,
+// in which case every allowed feature is granted. A nil policy grants nothing.
+func (p *PermissionPolicy) grant(playerUrl string, requested []string) []string {
+	if p == nil || playerUrl == "" {
+		return nil
+	}
+
+	u, err := url.Parse(playerUrl)
+	if err != nil {
+		return nil
+	}
+
+	allowed := p.allowedFor(u.Hostname())
+	if requested == nil {
+		return slices.Clone(allowed)
+	}
+
+	var granted []string
+	for _, feature := range requested {
+		if slices.Contains(allowed, feature) {
+			granted = append(granted, feature)
+		}
+	}
+	return granted
+}
@@ -0,0 +1,72 @@
+package summergo
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestParseAllowAttribute(t *testing.T) {
+	result := parseAllowAttribute("autoplay 'self'; camera 'none'; fullscreen https://example.com https://example.net;; Picture-In-Picture; autoplay")
+	expected := []string{"autoplay", "fullscreen", "picture-in-picture"}
+	if !slices.Equal(result, expected) {
+		t.Errorf("Expected: %v, Got: %v", expected, result)
+	}
+}
+
+func TestPermissionPolicyGrant(t *testing.T) {
+	policy := &PermissionPolicy{
+		Allowed: []string{"autoplay", "fullscreen"},
+		Hosts: map[string][]string{
+			"example.com":     {"autoplay", "fullscreen", "encrypted-media"},
+			"ads.example.com": {},
+		},
+	}
+
+	tests := []struct {
+		url       string
+		requested []string
+		expected  []string
+	}{
+		{url: "https://other.example.net/embed", requested: []string{"autoplay", "gyroscope"}, expected: []string{"autoplay"}},
+		{url: "https://other.example.net/embed", requested: nil, expected: []string{"autoplay", "fullscreen"}},
+		{url: "https://www.example.com/embed", requested: []string{"encrypted-media"}, expected: []string{"encrypted-media"}},
+		{url: "https://ads.example.com/embed", requested: nil, expected: nil},
+		{url: "", requested: nil, expected: nil},
+	}
+
+	for _, test := range tests {
+		result := policy.grant(test.url, test.requested)
+		if !slices.Equal(result, test.expected) {
+			t.Errorf("%s: Expected: %v, Got: %v", test.url, test.expected, result)
+		}
+	}
+
+	var nilPolicy *PermissionPolicy
+	if result := nilPolicy.grant("https://example.com/", nil); result != nil {
+		t.Errorf("nil policy should grant nothing: %v", result)
+	}
+}
+
+func TestSummarizerPermissionPolicy(t *testing.T) {
+	s := NewSummarizer(
+		WithOEmbed(false),
+		WithPermissionPolicy(&PermissionPolicy{Allowed: []string{"fullscreen"}}),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return newTestResponse(req, 200, "text/html; charset=utf-8", `<html><head>
+				<title>Video</title>
+				<meta name="twitter:player" content="https://player.example.com/embed/1">
+			</head></html>`), nil
+		})),
+	)
+
+	summary, err := s.Summarize("https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// metaタグのプレイヤーにもポリシーが適用される
+	if !slices.Equal(summary.Player.IframePermissions, []string{"fullscreen"}) {
+		t.Errorf("Expected: %v, Got: %v", []string{"fullscreen"}, summary.Player.IframePermissions)
+	}
+}
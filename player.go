@@ -0,0 +1,62 @@
+package summergo
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/nexryai/archer"
+)
+
+// PlayerValidationError describes why the player of a page was removed from its Summary.
+type PlayerValidationError struct {
+	Url    string
+	Reason string
+}
+
+func (e *PlayerValidationError) Error() string {
+	return "player rejected (" + e.Reason + "): " + e.Url
+}
+
+// hostがhostsのいずれか、またはそのサブドメインか
+func hostMatches(host string, hosts []string) bool {
+	host = strings.ToLower(host)
+	for host != "" {
+		if slices.Contains(hosts, host) {
+			return true
+		}
+
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			break
+		}
+		host = parent
+	}
+
+	return false
+}
+
+// validatePlayerUrl checks that playerUrl is safe to embed as an iframe.
+func (s *Summarizer) validatePlayerUrl(playerUrl string) error {
+	u, err := url.Parse(playerUrl)
+	if err != nil {
+		return &PlayerValidationError{Url: playerUrl, Reason: "invalid url"}
+	}
+
+	if u.Scheme != "https" {
+		return &PlayerValidationError{Url: playerUrl, Reason: "scheme is not https"}
+	} else if u.Hostname() == "" || u.User != nil {
+		return &PlayerValidationError{Url: playerUrl, Reason: "invalid host"}
+	} else if !archer.IsSafeUrl(playerUrl) {
+		// プライベートアドレスなどを埋め込ませない
+		return &PlayerValidationError{Url: playerUrl, Reason: "unsafe url"}
+	}
+
+	if hostMatches(u.Hostname(), s.deniedPlayerHosts) {
+		return &PlayerValidationError{Url: playerUrl, Reason: "host is denied"}
+	} else if len(s.allowedPlayerHosts) > 0 && !hostMatches(u.Hostname(), s.allowedPlayerHosts) {
+		return &PlayerValidationError{Url: playerUrl, Reason: "host is not allowed"}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package summergo
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestValidatePlayerUrl(t *testing.T) {
+	s := NewSummarizer(WithAllowedPlayerHosts("example.com", "video.example.net"), WithDeniedPlayerHosts("ads.example.com"))
+
+	tests := []struct {
+		url    string
+		reason string
+	}{
+		{url: "https://example.com/embed/1"},
+		{url: "https://www.example.com/embed/1"},
+		{url: "https://video.example.net/embed/1"},
+		{url: "http://example.com/embed/1", reason: "scheme is not https"},
+		{url: "javascript:alert(1)", reason: "scheme is not https"},
+		{url: "https://user@example.com/embed/1", reason: "invalid host"},
+		{url: "https://192.168.1.1/embed/1", reason: "unsafe url"},
+		{url: "https://ads.example.com/embed/1", reason: "host is denied"},
+		{url: "https://example.net/embed/1", reason: "host is not allowed"},
+	}
+
+	for _, test := range tests {
+		err := s.validatePlayerUrl(test.url)
+		if test.reason == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", test.url, err)
+			}
+			continue
+		}
+
+		var validationErr *PlayerValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: Expected: *PlayerValidationError, Got: %v", test.url, err)
+		} else if validationErr.Reason != test.reason {
+			t.Errorf("%s: Expected: %s, Got: %s", test.url, test.reason, validationErr.Reason)
+		}
+	}
+}
+
+func TestSummarizeRejectsUnsafePlayer(t *testing.T) {
+	s := NewSummarizer(
+		WithOEmbed(false),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return newTestResponse(req, 200, "text/html; charset=utf-8", `<html><head>
+				<title>Video</title>
+				<meta name="twitter:player" content="javascript:alert(1)">
+				<meta name="twitter:player:width" content="640">
+			</head></html>`), nil
+		})),
+	)
+
+	summary, err := s.Summarize("https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Player.Url != "" || summary.Player.Width != 0 || summary.Player.IframePermissions != nil {
+		t.Errorf("player should be cleared: %+v", summary.Player)
+	}
+
+	var validationErr *PlayerValidationError
+	if !errors.As(summary.PlayerError, &validationErr) {
+		t.Errorf("Expected: *PlayerValidationError, Got: %v", summary.PlayerError)
+	}
+}
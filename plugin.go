@@ -0,0 +1,54 @@
+package summergo
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+// Plugin summarizes pages of specific sites in place of the generic HTML pipeline.
+type Plugin interface {
+	// Match reports whether the plugin handles siteUrl.
+	Match(siteUrl *url.URL) bool
+	// Summarize builds a Summary for siteUrl.
+	// Returning a nil Summary and a nil error falls back to the generic pipeline.
+	Summarize(ctx context.Context, siteUrl *url.URL) (*Summary, error)
+}
+
+var (
+	pluginsMu sync.RWMutex
+	plugins   []Plugin
+)
+
+// RegisterPlugin adds p to the global plugin registry consulted by every Summarizer.
+// Plugins are tried in registration order after those given with WithPlugins.
+func RegisterPlugin(p Plugin) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+
+	plugins = append(plugins, p)
+}
+
+func registeredPlugins() []Plugin {
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+
+	return append([]Plugin(nil), plugins...)
+}
+
+// 最初にマッチしたプラグインを返す
+func (s *Summarizer) findPlugin(siteUrl *url.URL) Plugin {
+	for _, p := range s.plugins {
+		if p.Match(siteUrl) {
+			return p
+		}
+	}
+
+	for _, p := range registeredPlugins() {
+		if p.Match(siteUrl) {
+			return p
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package summergo
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+type testPlugin struct {
+	host    string
+	summary *Summary
+}
+
+func (p *testPlugin) Match(siteUrl *url.URL) bool {
+	return siteUrl.Host == p.host
+}
+
+func (p *testPlugin) Summarize(ctx context.Context, siteUrl *url.URL) (*Summary, error) {
+	return p.summary, nil
+}
+
+func TestSummarizerPlugins(t *testing.T) {
+	fetched := false
+	s := NewSummarizer(
+		WithPlugins(
+			&testPlugin{host: "plugin.example.com", summary: &Summary{Title: "From Plugin"}},
+			&testPlugin{host: "fallback.example.com"},
+		),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			fetched = true
+			return newTestResponse(req, 200, "text/html; charset=utf-8", testPageHtml), nil
+		})),
+		WithOEmbed(false),
+	)
+
+	// マッチしたプラグインの結果が使われる
+	summary, err := s.Summarize("https://plugin.example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Title != "From Plugin" {
+		t.Errorf("Expected: %s, Got: %s", "From Plugin", summary.Title)
+	}
+	if fetched {
+		t.Errorf("page should not be fetched when a plugin handles it")
+	}
+
+	// nilを返したら通常の処理にフォールバックする
+	summary, err = s.Summarize("https://fallback.example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Title != "Test Page" {
+		t.Errorf("Expected: %s, Got: %s", "Test Page", summary.Title)
+	}
+}
+
+func TestRegisterPlugin(t *testing.T) {
+	p := &testPlugin{host: "registered.example.com", summary: &Summary{Title: "Registered"}}
+	RegisterPlugin(p)
+	defer func() {
+		pluginsMu.Lock()
+		plugins = nil
+		pluginsMu.Unlock()
+	}()
+
+	u, _ := url.Parse("https://registered.example.com/")
+	if NewSummarizer().findPlugin(u) != p {
+		t.Errorf("registered plugin should be found")
+	}
+
+	u, _ = url.Parse("https://other.example.com/")
+	if NewSummarizer().findPlugin(u) != nil {
+		t.Errorf("unexpected plugin match")
+	}
+}
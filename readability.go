@@ -0,0 +1,286 @@
+package summergo
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// これより短い段落は本文とみなさない
+	minParagraphLength = 25
+	maxExcerptLength   = 300
+	// リンクが多すぎる段落はメニューなどとみなす
+	maxParagraphLinkDensity = 0.5
+)
+
+// Readability.jsを参考にしたclassとidの判定
+var (
+	unlikelyCandidates = regexp.MustCompile(`(?i)-ad-|ai2html|banner|breadcrumbs|combx|comment|community|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|related|remark|replies|rss|shoutbox|sidebar|skyscraper|social|sponsor|supplemental|ad-break|agegate|pagination|pager|popup|yom-remote|nav`)
+	maybeCandidates    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveHints      = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story`)
+	negativeHints      = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|widget`)
+)
+
+// 本文ではない要素
+var skippedContentTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"noscript": true,
+	"template": true,
+	"head":     true,
+	"nav":      true,
+	"header":   true,
+	"footer":   true,
+	"aside":    true,
+	"form":     true,
+	"button":   true,
+	"select":   true,
+	"textarea": true,
+	"iframe":   true,
+	"svg":      true,
+	"math":     true,
+}
+
+var unlikelyRoles = map[string]bool{
+	"menu":          true,
+	"menubar":       true,
+	"complementary": true,
+	"navigation":    true,
+	"alert":         true,
+	"alertdialog":   true,
+	"dialog":        true,
+}
+
+// これを含む要素は段落ではない
+var blockTags = map[string]bool{
+	"address":    true,
+	"article":    true,
+	"aside":      true,
+	"blockquote": true,
+	"div":        true,
+	"dl":         true,
+	"figure":     true,
+	"footer":     true,
+	"form":       true,
+	"h1":         true,
+	"h2":         true,
+	"h3":         true,
+	"h4":         true,
+	"h5":         true,
+	"h6":         true,
+	"header":     true,
+	"hr":         true,
+	"main":       true,
+	"nav":        true,
+	"ol":         true,
+	"p":          true,
+	"pre":        true,
+	"section":    true,
+	"table":      true,
+	"ul":         true,
+}
+
+func hasBlockChild(node *html.Node) bool {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && (blockTags[child.Data] || hasBlockChild(child)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isParagraph(node *html.Node) bool {
+	switch node.Data {
+	case "p", "pre":
+		return true
+	case "div", "section", "td", "blockquote":
+		return !hasBlockChild(node)
+	}
+	return false
+}
+
+func classAndId(node *html.Node) string {
+	return attrOrEmpty(node, "class") + " " + attrOrEmpty(node, "id")
+}
+
+// 広告やコメント欄など本文ではなさそうな要素
+func isUnlikelyContent(node *html.Node) bool {
+	if skippedContentTags[node.Data] {
+		return true
+	}
+	if _, hidden := getAttr(node, "hidden"); hidden {
+		return true
+	}
+	if ariaHidden, _ := getAttr(node, "aria-hidden"); ariaHidden == "true" {
+		return true
+	}
+	if role, _ := getAttr(node, "role"); unlikelyRoles[role] {
+		return true
+	}
+
+	if node.Data == "body" || node.Data == "article" || node.Data == "main" {
+		return false
+	}
+	match := classAndId(node)
+	return unlikelyCandidates.MatchString(match) && !maybeCandidates.MatchString(match)
+}
+
+func classWeight(node *html.Node) float64 {
+	weight := 0.0
+	for _, value := range []string{attrOrEmpty(node, "class"), attrOrEmpty(node, "id")} {
+		if value == "" {
+			continue
+		}
+		if negativeHints.MatchString(value) {
+			weight -= 25
+		}
+		if positiveHints.MatchString(value) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+func attrOrEmpty(node *html.Node, key string) string {
+	value, _ := getAttr(node, key)
+	return value
+}
+
+// 要素の種類による初期値
+func initialContentScore(node *html.Node) float64 {
+	score := classWeight(node)
+	switch node.Data {
+	case "div", "article", "main", "section":
+		score += 5
+	case "pre", "td", "blockquote":
+		score += 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		score -= 3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score -= 5
+	}
+	return score
+}
+
+// テキストのうちリンクが占める割合
+func linkDensity(node *html.Node) float64 {
+	length := utf8.RuneCountInString(textContent(node))
+	if length == 0 {
+		return 0
+	}
+
+	linkLength := 0
+	forEachElement(node, func(n *html.Node) {
+		if n.Data == "a" {
+			linkLength += utf8.RuneCountInString(textContent(n))
+		}
+	})
+	return float64(linkLength) / float64(length)
+}
+
+// 段落の点数
+// 長いほど、読点が多いほど文章らしい
+func paragraphScore(text string) float64 {
+	score := 1.0
+	for _, comma := range []string{",", "、", "，"} {
+		score += float64(strings.Count(text, comma))
+	}
+	score += min(float64(utf8.RuneCountInString(text)/100), 3)
+	return score
+}
+
+func isDescendant(node, ancestor *html.Node) bool {
+	for n := node; n != nil; n = n.Parent {
+		if n == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// 長すぎる場合は末尾を省略する
+func truncateExcerpt(text string) string {
+	if utf8.RuneCountInString(text) <= maxExcerptLength {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:maxExcerptLength-1])) + "…"
+}
+
+// extractExcerpt finds the main content of doc by scoring its paragraphs and their ancestors
+// by text length, commas, link density and class/id hints, and returns the beginning of it.
+// It returns an empty string when no paragraph looks like content.
+func extractExcerpt(doc *html.Node) string {
+	type paragraph struct {
+		node *html.Node
+		text string
+	}
+
+	var paragraphs []paragraph
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.ElementNode {
+			if isUnlikelyContent(node) {
+				return
+			}
+			if isParagraph(node) {
+				if text := textContent(node); utf8.RuneCountInString(text) >= minParagraphLength {
+					paragraphs = append(paragraphs, paragraph{node: node, text: text})
+				}
+				return
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	// 段落の点数を親には全部、祖父母には半分加える
+	scores := make(map[*html.Node]float64)
+	var candidates []*html.Node
+	for _, p := range paragraphs {
+		score := paragraphScore(p.text)
+		ancestor := p.node.Parent
+		for level := 0; level < 2 && ancestor != nil && ancestor.Type == html.ElementNode; level++ {
+			if _, ok := scores[ancestor]; !ok {
+				scores[ancestor] = initialContentScore(ancestor)
+				candidates = append(candidates, ancestor)
+			}
+			scores[ancestor] += score / float64(level+1)
+			ancestor = ancestor.Parent
+		}
+	}
+
+	var best *html.Node
+	bestScore := 0.0
+	for _, candidate := range candidates {
+		score := scores[candidate] * (1 - linkDensity(candidate))
+		if best == nil || score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	if best == nil {
+		return ""
+	}
+
+	// 本文の先頭の段落から要約を作る
+	var excerpt strings.Builder
+	for _, p := range paragraphs {
+		if !isDescendant(p.node, best) || linkDensity(p.node) > maxParagraphLinkDensity {
+			continue
+		}
+		if excerpt.Len() > 0 {
+			excerpt.WriteByte(' ')
+		}
+		excerpt.WriteString(p.text)
+		if utf8.RuneCountInString(excerpt.String()) >= maxExcerptLength {
+			break
+		}
+	}
+
+	return truncateExcerpt(excerpt.String())
+}
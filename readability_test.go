@@ -0,0 +1,70 @@
+package summergo
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+const testArticlePageHtml = `<html><head><title>Article</title></head><body>
+	<nav><ul><li><a href="/">Home</a></li><li><a href="/news">News, sports, weather and everything else</a></li></ul></nav>
+	<div class="sidebar">
+		<p>Popular posts, recommended posts, and more posts you may like to read.</p>
+	</div>
+	<div id="main-content" class="article-body">
+		<h1>Title of the article</h1>
+		<p>The first paragraph of the article explains what happened, where, and why it matters.</p>
+		<p>The second paragraph goes into detail, quoting several people, and adds <a href="/more">a link</a>.</p>
+		<p><a href="/a">Related link one that is long enough</a> <a href="/b">and another</a></p>
+	</div>
+	<div class="comments">
+		<p>This is a comment, which is long, opinionated, and not part of the article at all.</p>
+		<p>This is another comment, which is also long, and should never become the description.</p>
+	</div>
+	<footer><p>Copyright, all rights reserved, by the example company and its friends.</p></footer>
+</body></html>`
+
+func TestExtractExcerpt(t *testing.T) {
+	doc := parseTestHtml(t, testArticlePageHtml)
+
+	expected := "The first paragraph of the article explains what happened, where, and why it matters. The second paragraph goes into detail, quoting several people, and adds a link."
+	if result := extractExcerpt(doc); result != expected {
+		t.Errorf("Expected: %s, Got: %s", expected, result)
+	}
+
+	// 本文らしい段落がなければ空
+	doc = parseTestHtml(t, `<html><body><p>Short</p><ul><li><a href="/">Home</a></li></ul></body></html>`)
+	if result := extractExcerpt(doc); result != "" {
+		t.Errorf("Expected empty result, Got: %s", result)
+	}
+
+	// 日本語の長い本文は省略する
+	doc = parseTestHtml(t, `<html><body><div><p>`+strings.Repeat("吾輩は猫である、名前はまだ無い。", 40)+`</p></div></body></html>`)
+	result := extractExcerpt(doc)
+	if utf8.RuneCountInString(result) != maxExcerptLength || !strings.HasSuffix(result, "…") {
+		t.Errorf("excerpt should be truncated: %s", result)
+	}
+}
+
+func TestSummarizeHtmlContentExtraction(t *testing.T) {
+	siteUrl, _ := url.Parse("https://example.com/articles/1")
+
+	s := NewSummarizer(WithOEmbed(false), WithManifest(false))
+	summary, err := s.SummarizeHtml(*siteUrl, strings.NewReader(testArticlePageHtml), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(summary.Description, "The first paragraph of the article") {
+		t.Errorf("description should be extracted from the content: %s", summary.Description)
+	}
+
+	s = NewSummarizer(WithOEmbed(false), WithManifest(false), WithContentExtraction(false))
+	summary, err = s.SummarizeHtml(*siteUrl, strings.NewReader(testArticlePageHtml), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Description != "" {
+		t.Errorf("Expected empty description, Got: %s", summary.Description)
+	}
+}
@@ -0,0 +1,194 @@
+package summergo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// headに現れる要素
+// これ以外の要素が出てきたら本文が始まったとみなす
+var headTags = map[string]bool{
+	"html":     true,
+	"head":     true,
+	"meta":     true,
+	"link":     true,
+	"title":    true,
+	"base":     true,
+	"script":   true,
+	"style":    true,
+	"noscript": true,
+	"template": true,
+}
+
+// scanHead indexes the metadata elements in the head of a document.
+// It stops reading z at </head> or at the first content that belongs to the body.
+func scanHead(z *html.Tokenizer) (*metaIndex, error) {
+	idx := newEmptyMetaIndex()
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			return idx, nil
+		case html.TextToken:
+			if strings.TrimSpace(string(z.Text())) != "" {
+				return idx, nil
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "head" {
+				return idx, nil
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := z.Token()
+			if !headTags[token.Data] {
+				return idx, nil
+			}
+
+			switch token.Data {
+			case "meta", "link", "base":
+				idx.add(token.Data, token.Attr, "")
+			case "title":
+				// titleの中身は次のテキストトークン
+				var text string
+				if tt == html.StartTagToken && z.Next() == html.TextToken {
+					text = z.Token().Data
+				}
+				idx.add("title", token.Attr, text)
+			case "script", "style", "noscript":
+				// 中身のテキストは本文ではないので読み飛ばす
+				// JSON-LDだけは取っておく
+				if tt == html.StartTagToken && z.Next() == html.TextToken && token.Data == "script" && isJsonLdScript(token.Attr) {
+					idx.jsonLd = append(idx.jsonLd, string(z.Text()))
+				}
+			}
+		}
+	}
+}
+
+// headの後に読む本文の大きさの上限
+// 記事のJSON-LDやmetaタグは本文の先頭付近にあることが多い
+const maxBodyScanSize = 256 * 1024
+
+// scanBody indexes the meta and link elements and the JSON-LD scripts in the rest of the document
+// without building a tree.
+func scanBody(z *html.Tokenizer, idx *metaIndex) error {
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return err
+			}
+			return nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			tagName := string(name)
+			if tagName != "meta" && tagName != "link" && tagName != "script" {
+				continue
+			}
+
+			var attrs []html.Attribute
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				attrs = append(attrs, html.Attribute{Key: string(key), Val: string(val)})
+			}
+
+			if tagName != "script" {
+				idx.add(tagName, attrs, "")
+			} else if tt == html.StartTagToken && isJsonLdScript(attrs) && z.Next() == html.TextToken {
+				idx.jsonLd = append(idx.jsonLd, string(z.Text()))
+			}
+		}
+	}
+}
+
+// headだけで要約に必要な情報が揃っているか
+func (idx *metaIndex) hasRequiredFields() bool {
+	return getPageTitle(idx) != "" && getPageDescription(idx) != ""
+}
+
+// metaタグかJSON-LDにサムネイルがあるか
+func (idx *metaIndex) hasThumbnail() bool {
+	return getPageImage(idx) != "" || summarizeStructuredData(parseJsonLd(idx.jsonLd)).thumbnail != ""
+}
+
+// 読んだ分を記録するReader
+// 全体をパースし直すときに先頭から読み直すために使う
+type recordingReader struct {
+	r        io.Reader
+	recorded bytes.Buffer
+	// これ以上読まない残りのバイト数
+	// 負の場合は制限しない
+	remaining int64
+}
+
+func (rr *recordingReader) Read(p []byte) (int, error) {
+	if rr.remaining == 0 {
+		return 0, io.EOF
+	} else if rr.remaining > 0 && int64(len(p)) > rr.remaining {
+		p = p[:rr.remaining]
+	}
+
+	n, err := rr.r.Read(p)
+	rr.recorded.Write(p[:n])
+	if rr.remaining > 0 {
+		rr.remaining -= int64(n)
+	}
+	return n, err
+}
+
+func parseDocument(ctx context.Context, r io.Reader) (*metaIndex, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, errors.New("failed to parse html")
+	}
+
+	// 要素の検索のたびに木を辿らないように一度だけインデックスを作る
+	return newMetaIndex(doc), nil
+}
+
+// HTMLを読み込んでインデックスを作る
+// まずheadだけを読み、タイトルか説明がなければ残りも読んで全体をパースする
+// サムネイルや構造化データが足りない場合は本文の先頭だけを読む
+func (s *Summarizer) parseHtml(ctx context.Context, body io.Reader, charSet string) (*metaIndex, error) {
+	decoded, err := newDecodingReader(&contextReader{ctx: ctx, r: body}, charSet)
+	if err != nil {
+		return nil, err
+	}
+
+	rr := &recordingReader{r: decoded, remaining: -1}
+	z := html.NewTokenizer(rr)
+	idx, err := scanHead(z)
+	if err != nil {
+		return nil, err
+	}
+
+	if !idx.hasRequiredFields() {
+		return parseDocument(ctx, io.MultiReader(&rr.recorded, decoded))
+	} else if len(idx.jsonLd) > 0 && idx.hasThumbnail() {
+		return idx, nil
+	}
+
+	// 本文の先頭から木を作らずにJSON-LDと記事のmetaタグを集める
+	rr.remaining = maxBodyScanSize
+	if err := scanBody(z, idx); err != nil {
+		return nil, err
+	} else if idx.hasThumbnail() {
+		return idx, nil
+	}
+
+	// microdataの画像を探すために読んだ範囲だけをパースする
+	return parseDocument(ctx, bytes.NewReader(rr.recorded.Bytes()))
+}
@@ -0,0 +1,134 @@
+package summergo
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// 読み込んだバイト数を数えるReader
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += n
+	return n, err
+}
+
+// 巨大な本文を持つページ
+func newLargeBodyReader(head string) *countingReader {
+	body := strings.Repeat("<p>content</p>", 1024*1024)
+	return &countingReader{r: strings.NewReader("<html><head>" + head + "</head><body>" + body + "</body></html>")}
+}
+
+func TestScanHead(t *testing.T) {
+	r := newLargeBodyReader(`<meta charset="utf-8"><title>Head &amp; Title</title><script>document.write("<div>")</script><meta property="og:description" content="Description">`)
+	idx, err := scanHead(html.NewTokenizer(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := getPageTitle(idx); result != "Head & Title" {
+		t.Errorf("Expected: %s, Got: %s", "Head & Title", result)
+	}
+	if result := getPageDescription(idx); result != "Description" {
+		t.Errorf("Expected: %s, Got: %s", "Description", result)
+	}
+	if r.n > 64*1024 {
+		t.Errorf("body should not be read: %d bytes", r.n)
+	}
+
+	// headがなくても本文が始まったら止まる
+	idx, err = scanHead(html.NewTokenizer(strings.NewReader(`<title>Title</title><div><meta name="description" content="In Body"></div>`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := getPageDescription(idx); result != "" {
+		t.Errorf("Expected empty result, Got: %s", result)
+	}
+}
+
+func TestParseHtmlStreaming(t *testing.T) {
+	siteUrl, _ := url.Parse("https://example.com/")
+	s := NewSummarizer(WithOEmbed(false))
+
+	tests := []struct {
+		head     string
+		maxBytes int
+	}{
+		// サムネイルとJSON-LDがなければ本文の先頭だけを読む
+		{head: `<title>Title</title><meta name="description" content="Description">`, maxBytes: 512 * 1024},
+		{head: `<title>Title</title><meta name="description" content="Description"><meta property="og:image" content="/image.png">`, maxBytes: 512 * 1024},
+		// 揃っていれば本文は読まない
+		{
+			head: `<title>Title</title><meta name="description" content="Description"><meta property="og:image" content="/image.png">` +
+				`<script type="application/ld+json">{"@type": "NewsArticle", "datePublished": "2024-01-02"}</script>`,
+			maxBytes: 128 * 1024,
+		},
+	}
+
+	for _, test := range tests {
+		r := newLargeBodyReader(test.head)
+		summary, err := s.SummarizeHtmlContext(context.Background(), *siteUrl, r, "utf-8")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summary.Title != "Title" || summary.Description != "Description" {
+			t.Errorf("unexpected summary: %+v", summary)
+		}
+		if r.n > test.maxBytes {
+			t.Errorf("%s: body should not be read when head has enough metadata: %d bytes", test.head, r.n)
+		}
+	}
+}
+
+func TestParseHtmlScanBody(t *testing.T) {
+	s := NewSummarizer(WithOEmbed(false))
+
+	// headにJSON-LDがなければ木を作らずに本文のJSON-LDとmetaタグを集める
+	page := `<html><head><title>Title</title><meta name="description" content="Description"><meta property="og:image" content="/image.png"></head>
+		<body><p>text</p><meta property="article:section" content="News">
+		<script>var s = "<meta property='article:tag' content='Script'>";</script>
+		<script type="application/ld+json">{"@type": "NewsArticle", "author": {"@type": "Person", "name": "Alice"}}</script></body></html>`
+	idx, err := s.parseHtml(context.Background(), strings.NewReader(page), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.doc != nil {
+		t.Errorf("document should not be parsed when head has enough metadata")
+	}
+	if len(idx.jsonLd) != 1 {
+		t.Errorf("Expected: %d, Got: %d", 1, len(idx.jsonLd))
+	}
+	if result := idx.find(&findParam{tagName: "meta", attrKey: "property", attrValue: "article:section", targetKey: "content"}); result != "News" {
+		t.Errorf("Expected: %s, Got: %s", "News", result)
+	}
+	if result := idx.find(&findParam{tagName: "meta", attrKey: "property", attrValue: "article:tag", targetKey: "content"}); result != "" {
+		t.Errorf("Expected empty result, Got: %s", result)
+	}
+}
+
+func TestParseHtmlFallback(t *testing.T) {
+	siteUrl, _ := url.Parse("https://example.com/")
+	s := NewSummarizer(WithOEmbed(false))
+
+	// 本文にあるmetaタグは全体をパースして読む
+	page := `<html><head><title>Title</title></head><body><p>text</p><meta name="description" content="In Body"></body></html>`
+	summary, err := s.SummarizeHtml(*siteUrl, strings.NewReader(page), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Title != "Title" {
+		t.Errorf("Expected: %s, Got: %s", "Title", summary.Title)
+	}
+	if summary.Description != "In Body" {
+		t.Errorf("Expected: %s, Got: %s", "In Body", summary.Description)
+	}
+}
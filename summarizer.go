@@ -0,0 +1,300 @@
+package summergo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nexryai/archer"
+)
+
+const (
+	defaultTimeout     = 10 * time.Second
+	defaultMaxBodySize = 1024 * 1024 * 10
+	defaultUserAgent   = "Mozilla/5.0 (compatible; SummerGo/0.1;)"
+)
+
+// Summarizer fetches web pages and builds a Summary from them.
+// The zero value is not usable; create one with NewSummarizer.
+type Summarizer struct {
+	timeout     time.Duration
+	maxBodySize int64
+	userAgent   string
+	oEmbed      bool
+	// oEmbedだけで要約を作れる場合はページを取得しない
+	oEmbedShortcut     bool
+	oembedProviders    []*oembedEndpointMatcher
+	permissionPolicy   *PermissionPolicy
+	allowedPlayerHosts []string
+	deniedPlayerHosts  []string
+	manifest           bool
+	iconSize           int
+	verifyIcon         bool
+	contentExtraction  bool
+	transport          http.RoundTripper
+	plugins            []Plugin
+	cache              Cache
+	cacheMinTTL        time.Duration
+	cacheMaxTTL        time.Duration
+	flight             flightGroup
+}
+
+// Option configures a Summarizer.
+type Option func(*Summarizer)
+
+// WithTimeout sets the overall deadline shared by all requests made for one summary.
+// A timeout of zero or less disables the deadline.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Summarizer) {
+		s.timeout = timeout
+	}
+}
+
+// WithMaxBodySize sets the maximum number of bytes read from a response body.
+func WithMaxBodySize(size int64) Option {
+	return func(s *Summarizer) {
+		s.maxBodySize = size
+	}
+}
+
+// WithUserAgent sets the default User-Agent header.
+// Some hosts that need a specific User-Agent to return metadata ignore this value.
+func WithUserAgent(userAgent string) Option {
+	return func(s *Summarizer) {
+		s.userAgent = userAgent
+	}
+}
+
+// WithOEmbed enables or disables fetching oEmbed documents for players.
+func WithOEmbed(enabled bool) Option {
+	return func(s *Summarizer) {
+		s.oEmbed = enabled
+	}
+}
+
+// WithOEmbedProviders replaces the built-in oEmbed provider list used for pages
+// that do not advertise their oEmbed endpoint.
+func WithOEmbedProviders(providers []OEmbedProvider) Option {
+	return func(s *Summarizer) {
+		s.oembedProviders = compileOEmbedProviders(providers)
+	}
+}
+
+// WithOEmbedShortcut makes the Summarizer query the oEmbed endpoint of a known provider first
+// and skip fetching the page when the response has everything a Summary needs.
+func WithOEmbedShortcut(enabled bool) Option {
+	return func(s *Summarizer) {
+		s.oEmbedShortcut = enabled
+	}
+}
+
+// WithPermissionPolicy sets the policy deciding which iframe permissions players are granted.
+// A nil policy grants no permissions.
+func WithPermissionPolicy(policy *PermissionPolicy) Option {
+	return func(s *Summarizer) {
+		s.permissionPolicy = policy
+	}
+}
+
+// WithAllowedPlayerHosts restricts players to the given hosts and their subdomains.
+func WithAllowedPlayerHosts(hosts ...string) Option {
+	return func(s *Summarizer) {
+		for _, host := range hosts {
+			s.allowedPlayerHosts = append(s.allowedPlayerHosts, strings.ToLower(host))
+		}
+	}
+}
+
+// WithDeniedPlayerHosts rejects players of the given hosts and their subdomains.
+func WithDeniedPlayerHosts(hosts ...string) Option {
+	return func(s *Summarizer) {
+		for _, host := range hosts {
+			s.deniedPlayerHosts = append(s.deniedPlayerHosts, strings.ToLower(host))
+		}
+	}
+}
+
+// WithManifest enables or disables fetching the Web App Manifest linked from pages.
+func WithManifest(enabled bool) Option {
+	return func(s *Summarizer) {
+		s.manifest = enabled
+	}
+}
+
+// WithIconSize sets the size in pixels the icon is displayed at.
+// The icon closest to this size is preferred, and larger icons are preferred over smaller ones.
+func WithIconSize(size int) Option {
+	return func(s *Summarizer) {
+		s.iconSize = size
+	}
+}
+
+// WithIconVerification makes the Summarizer check that the chosen icon exists
+// and fall back to the next candidate if it does not.
+// Icon is left empty when no candidate can be fetched.
+func WithIconVerification(enabled bool) Option {
+	return func(s *Summarizer) {
+		s.verifyIcon = enabled
+	}
+}
+
+// WithContentExtraction enables or disables deriving the description from the main text
+// of the page when it has no description metadata.
+func WithContentExtraction(enabled bool) Option {
+	return func(s *Summarizer) {
+		s.contentExtraction = enabled
+	}
+}
+
+// WithTransport sets the http.RoundTripper used to send requests.
+// URLs are still checked with archer.IsSafeUrl, but the transport is responsible
+// for rejecting connections to private addresses.
+// By default, each Summarizer uses its own transport that refuses to dial them.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(s *Summarizer) {
+		s.transport = transport
+	}
+}
+
+// WithPlugins adds plugins that are tried before the global plugin registry.
+func WithPlugins(plugins ...Plugin) Option {
+	return func(s *Summarizer) {
+		s.plugins = append(s.plugins, plugins...)
+	}
+}
+
+// WithCache enables caching summaries in cache.
+func WithCache(cache Cache) Option {
+	return func(s *Summarizer) {
+		s.cache = cache
+	}
+}
+
+// WithCacheTTL sets the bounds applied to the TTL derived from the origin's Cache-Control and Expires headers.
+// Responses without freshness information are cached for minTTL,
+// and responses marked no-store, no-cache or private are not cached.
+func WithCacheTTL(minTTL, maxTTL time.Duration) Option {
+	return func(s *Summarizer) {
+		s.cacheMinTTL = minTTL
+		s.cacheMaxTTL = maxTTL
+	}
+}
+
+// NewSummarizer creates a Summarizer with the given options applied over the defaults.
+func NewSummarizer(opts ...Option) *Summarizer {
+	s := &Summarizer{
+		timeout:           defaultTimeout,
+		maxBodySize:       defaultMaxBodySize,
+		userAgent:         defaultUserAgent,
+		oEmbed:            true,
+		oembedProviders:   defaultOEmbedMatchers(),
+		permissionPolicy:  DefaultPermissionPolicy(),
+		manifest:          true,
+		iconSize:          defaultIconSize,
+		contentExtraction: true,
+		cacheMinTTL:       defaultCacheMinTTL,
+		cacheMaxTTL:       defaultCacheMaxTTL,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// 接続先の検証はSummarizerごとのTransportで行う
+	if s.transport == nil {
+		s.transport = newSafeTransport()
+	}
+
+	return s
+}
+
+var defaultSummarizer = NewSummarizer()
+
+func (s *Summarizer) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.timeout)
+}
+
+// キャンセルされたら読み込みを中断するReader
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// サイズ制限付きのReadCloser
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func (s *Summarizer) send(req *http.Request) (*http.Response, error) {
+	if !archer.IsSafeUrl(req.URL.String()) {
+		return nil, archer.ErrUnsafeUrlDetected
+	}
+
+	client := &http.Client{
+		Transport: s.transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			// リダイレクト先も検証する
+			if !archer.IsSafeUrl(req.URL.String()) {
+				return archer.ErrUnsafeUrlDetected
+			}
+			return nil
+		},
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Header.Get("Blocked-By") == "NextDNS" {
+		_ = resp.Body.Close()
+		return nil, archer.ErrBlockedByDNS
+	}
+
+	contentLength := resp.Header.Get("Content-Length")
+	if contentLength != "" {
+		length, err := strconv.ParseInt(contentLength, 10, 64)
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, err
+		}
+		if length > s.maxBodySize {
+			_ = resp.Body.Close()
+			return nil, ErrBodyTooLarge
+		}
+	}
+
+	resp.Body = &limitedReadCloser{Reader: io.LimitReader(resp.Body, s.maxBodySize), Closer: resp.Body}
+	return resp, nil
+}
+
+type languageKey struct{}
+
+// ContextWithLanguage returns a copy of ctx that requests pages in lang.
+// The value is sent as the Accept-Language header.
+func ContextWithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+func languageFromContext(ctx context.Context) string {
+	lang, _ := ctx.Value(languageKey{}).(string)
+	return lang
+}
@@ -0,0 +1,147 @@
+package summergo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newTestResponse(req *http.Request, statusCode int, contentType string, body string) *http.Response {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	return &http.Response{
+		StatusCode: statusCode,
+		Status:     http.StatusText(statusCode),
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+const testPageHtml = `<html>
+	<head>
+		<title>Test Page</title>
+		<meta property="og:description" content="Test Description">
+		<link type="application/json+oembed" href="https://example.com/oembed">
+	</head>
+	<body></body>
+</html>`
+
+func TestNewSummarizer(t *testing.T) {
+	s := NewSummarizer()
+	if s.timeout != defaultTimeout || s.maxBodySize != defaultMaxBodySize || s.userAgent != defaultUserAgent || !s.oEmbed {
+		t.Errorf("unexpected defaults: %+v", s)
+	}
+
+	s = NewSummarizer(WithTimeout(3*time.Second), WithMaxBodySize(1024), WithUserAgent("TestAgent/1.0"), WithOEmbed(false))
+	if s.timeout != 3*time.Second || s.maxBodySize != 1024 || s.userAgent != "TestAgent/1.0" || s.oEmbed {
+		t.Errorf("options are not applied: %+v", s)
+	}
+}
+
+func TestSummarizerWithTransport(t *testing.T) {
+	var userAgent string
+	oembedRequested := false
+
+	s := NewSummarizer(
+		WithUserAgent("TestAgent/1.0"),
+		WithOEmbed(false),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/oembed" {
+				oembedRequested = true
+				return newTestResponse(req, 404, "", ""), nil
+			}
+
+			userAgent = req.Header.Get("User-Agent")
+			return newTestResponse(req, 200, "text/html; charset=utf-8", testPageHtml), nil
+		})),
+	)
+
+	summary, err := s.Summarize("https://example.com/page")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Title != "Test Page" {
+		t.Errorf("Expected: %s, Got: %s", "Test Page", summary.Title)
+	}
+	if userAgent != "TestAgent/1.0" {
+		t.Errorf("Expected: %s, Got: %s", "TestAgent/1.0", userAgent)
+	}
+	if oembedRequested {
+		t.Errorf("oEmbed should not be requested when disabled")
+	}
+}
+
+func TestSummarizerMaxBodySize(t *testing.T) {
+	s := NewSummarizer(
+		WithMaxBodySize(16),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := newTestResponse(req, 200, "text/html", testPageHtml)
+			resp.Header.Set("Content-Length", "1024")
+			return resp, nil
+		})),
+	)
+
+	if _, err := s.Summarize("https://example.com/"); err == nil {
+		t.Errorf("summarize should be failed when the body exceeds the limit")
+	}
+}
+
+func TestSummarizeContextCanceled(t *testing.T) {
+	s := NewSummarizer(WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := s.SummarizeContext(ctx, "https://example.com/")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected: %v, Got: %v", context.Canceled, err)
+	}
+}
+
+func TestSummarizeContextSharedDeadline(t *testing.T) {
+	// ページの取得後、oEmbedの取得でブロックさせる
+	s := NewSummarizer(
+		WithTimeout(200*time.Millisecond),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/oembed" {
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			}
+
+			time.Sleep(100 * time.Millisecond)
+			return newTestResponse(req, 200, "text/html; charset=utf-8", testPageHtml), nil
+		})),
+	)
+
+	start := time.Now()
+	_, err := s.SummarizeContext(context.Background(), "https://example.com/")
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected: %v, Got: %v", context.DeadlineExceeded, err)
+	}
+	if elapsed > 400*time.Millisecond {
+		t.Errorf("deadline should be shared between requests: %v", elapsed)
+	}
+}
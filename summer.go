@@ -1,22 +1,18 @@
 package summergo
 
 import (
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
-	"github.com/nexryai/archer"
-	"github.com/saintfish/chardet"
-	"golang.org/x/net/html"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
-	"unicode/utf8"
+	"time"
 )
 
-func getPageTitle(doc *html.Node) string {
-	return analyzeNode(doc, []*findParam{
+func getPageTitle(idx *metaIndex) string {
+	return idx.find([]*findParam{
 		{tagName: "meta", attrKey: "property", attrValue: "og:title", targetKey: "content"},
 		{tagName: "meta", attrKey: "name", attrValue: "twitter:title", targetKey: "content"},
 		{tagName: "meta", attrKey: "property", attrValue: "twitter:title", targetKey: "content"},
@@ -24,8 +20,8 @@ func getPageTitle(doc *html.Node) string {
 	}...)
 }
 
-func getPageDescription(doc *html.Node) string {
-	return analyzeNode(doc, []*findParam{
+func getPageDescription(idx *metaIndex) string {
+	return idx.find([]*findParam{
 		{tagName: "meta", attrKey: "property", attrValue: "og:description", targetKey: "content"},
 		{tagName: "meta", attrKey: "name", attrValue: "twitter:description", targetKey: "content"},
 		{tagName: "meta", attrKey: "property", attrValue: "twitter:description", targetKey: "content"},
@@ -33,8 +29,8 @@ func getPageDescription(doc *html.Node) string {
 	}...)
 }
 
-func getPageImage(doc *html.Node) string {
-	return analyzeNode(doc, []*findParam{
+func getPageImage(idx *metaIndex) string {
+	return idx.find([]*findParam{
 		{tagName: "meta", attrKey: "property", attrValue: "og:image", targetKey: "content"},
 		{tagName: "meta", attrKey: "name", attrValue: "twitter:image", targetKey: "content"},
 		{tagName: "meta", attrKey: "property", attrValue: "twitter:image", targetKey: "content"},
@@ -44,81 +40,79 @@ func getPageImage(doc *html.Node) string {
 	}...)
 }
 
-func getPlayerFromOEmbed(doc *html.Node) *Player {
-	oembedUrl := analyzeNode(doc, []*findParam{
+func getOEmbedUrl(idx *metaIndex) string {
+	// JSONを優先する
+	jsonUrl := idx.find([]*findParam{
 		{tagName: "link", attrKey: "type", attrValue: "application/json+oembed", targetKey: "href"},
 	}...)
-
-	if oembedUrl == "" {
-		return nil
+	if jsonUrl != "" {
+		return jsonUrl
 	}
 
-	// OEmbedを取得する
-	req, newReqErr := http.NewRequest("GET", oembedUrl, nil)
-	if newReqErr != nil {
-		return nil
-	}
-
-	req.Header.Set("User-Agent", "SummerGo/0.1")
+	return idx.find([]*findParam{
+		{tagName: "link", attrKey: "type", attrValue: "text/xml+oembed", targetKey: "href"},
+		{tagName: "link", attrKey: "type", attrValue: "application/xml+oembed", targetKey: "href"},
+	}...)
+}
 
-	requester := archer.SecureRequest{
-		Request:     req,
-		TimeoutSecs: 10,
-		MaxSize:     1024 * 1024 * 10,
+// oEmbedからプレイヤーとiframeが要求する権限を取得する
+// 権限がわからない場合はnilを返す
+func getPlayerFromOEmbed(embed *oembed, idx *metaIndex) (*Player, []string) {
+	player := &Player{
+		Url:    getPlayerUrl(idx),
+		Width:  int(embed.Width),
+		Height: int(embed.Height),
 	}
 
-	resp, respErr := requester.Send()
-
-	if respErr != nil || resp.StatusCode != 200 {
-		return nil
+	iframe := parseOEmbedIframe(embed.Html)
+	if iframe == nil {
+		return player, nil
 	}
 
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			panic(err)
-		}
-	}(resp.Body)
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil
+	// ページでプレイヤーが指定されていなければiframeのsrcを使う
+	if player.Url == "" {
+		player.Url = iframe.src
+	}
+	if player.Width == 0 {
+		player.Width = iframe.width
+	}
+	if player.Height == 0 {
+		player.Height = iframe.height
 	}
 
-	embed := &oembed{}
-	err = json.Unmarshal(body, embed)
-	if err != nil {
+	return player, iframe.permissions()
+}
+
+// プレイヤーのURLの書き換えと検証、権限の設定を行う
+// 検証に失敗した場合はプレイヤーを空にしてその理由を返す
+func (s *Summarizer) preparePlayer(player *Player, requested []string) error {
+	if player.Url == "" {
+		*player = Player{}
 		return nil
 	}
 
-	// OEmbedのiframeが要求する権限のうち安全なものを許可する
-	var allowed []string
-	safePermissions := []string{"autoplay", "clipboard-write", "picture-in-picture", "web-share", "fullscreen"}
-	for _, rp := range getRequiredPermissionsFromIframe(embed.Html) {
-		for _, sp := range safePermissions {
-			if rp == sp {
-				allowed = append(allowed, rp)
-			}
-		}
+	if strings.Contains(player.Url, "youtube.com/embed/") {
+		// プライバシー保護のためwww.youtube-nocookie.comに置き換える
+		player.Url = strings.Replace(player.Url, "youtube.com/embed/", "youtube-nocookie.com/embed/", 1)
 	}
 
-	return &Player{
-		Url:               getPlayerUrl(doc),
-		Width:             embed.Width,
-		Height:            embed.Height,
-		IframePermissions: allowed,
+	if err := s.validatePlayerUrl(player.Url); err != nil {
+		*player = Player{}
+		return err
 	}
 
+	player.IframePermissions = s.permissionPolicy.grant(player.Url, requested)
+	return nil
 }
 
-func getPlayerUrl(doc *html.Node) string {
-	twitterCard := analyzeNode(doc, []*findParam{
+func getPlayerUrl(idx *metaIndex) string {
+	twitterCard := idx.find([]*findParam{
 		{tagName: "meta", attrKey: "name", attrValue: "twitter:card", targetKey: "content"},
 		{tagName: "meta", attrKey: "property", attrValue: "twitter:card", targetKey: "content"},
 	}...)
 
 	if twitterCard != "summary_large_image" {
-		playerUrlFromTwitterCard := analyzeNode(doc, []*findParam{
+		playerUrlFromTwitterCard := idx.find([]*findParam{
 			{tagName: "meta", attrKey: "name", attrValue: "twitter:player", targetKey: "content"},
 			{tagName: "meta", attrKey: "property", attrValue: "twitter:player", targetKey: "content"},
 		}...)
@@ -128,15 +122,15 @@ func getPlayerUrl(doc *html.Node) string {
 		}
 	}
 
-	return analyzeNode(doc, []*findParam{
+	return idx.find([]*findParam{
 		{tagName: "meta", attrKey: "property", attrValue: "og:video", targetKey: "content"},
 		{tagName: "meta", attrKey: "property", attrValue: "og:video:secure_url", targetKey: "content"},
 		{tagName: "meta", attrKey: "property", attrValue: "og:video:url", targetKey: "content"},
 	}...)
 }
 
-func getPlayerWidth(doc *html.Node) int {
-	widthStr := analyzeNode(doc, []*findParam{
+func getPlayerWidth(idx *metaIndex) int {
+	widthStr := idx.find([]*findParam{
 		{tagName: "meta", attrKey: "name", attrValue: "twitter:player:width", targetKey: "content"},
 		{tagName: "meta", attrKey: "property", attrValue: "twitter:player:width", targetKey: "content"},
 		{tagName: "meta", attrKey: "property", attrValue: "og:video:width", targetKey: "content"},
@@ -150,8 +144,8 @@ func getPlayerWidth(doc *html.Node) int {
 	return w
 }
 
-func getPlayerHeight(doc *html.Node) int {
-	heightStr := analyzeNode(doc, []*findParam{
+func getPlayerHeight(idx *metaIndex) int {
+	heightStr := idx.find([]*findParam{
 		{tagName: "meta", attrKey: "name", attrValue: "twitter:player:height", targetKey: "content"},
 		{tagName: "meta", attrKey: "property", attrValue: "twitter:player:height", targetKey: "content"},
 		{tagName: "meta", attrKey: "property", attrValue: "og:video:height", targetKey: "content"},
@@ -165,142 +159,308 @@ func getPlayerHeight(doc *html.Node) int {
 	return h
 }
 
-func getActivityPubLink(doc *html.Node) string {
-	return analyzeNode(doc, []*findParam{
+func getActivityPubLink(idx *metaIndex) string {
+	return idx.find([]*findParam{
 		{tagName: "link", attrKey: "type", attrValue: "application/activity+json", targetKey: "href"},
 	}...)
 }
 
-func isSensitive(doc *html.Node, parsedUrl url.URL) bool {
-	if strings.Contains("mixi.co.jp", parsedUrl.Host) && analyzeNode(doc, []*findParam{{tagName: "meta", attrKey: "property", attrValue: "mixi:content-rating", targetKey: "content"}}...) == "1" {
+func isSensitive(idx *metaIndex, parsedUrl url.URL) bool {
+	if strings.Contains("mixi.co.jp", parsedUrl.Host) && idx.find([]*findParam{{tagName: "meta", attrKey: "property", attrValue: "mixi:content-rating", targetKey: "content"}}...) == "1" {
 		return true
 	} else {
 		return false
 	}
 }
 
-func getSiteName(doc *html.Node, parsedUrl url.URL) string {
-	res := analyzeNode(doc, []*findParam{
+func getSiteName(idx *metaIndex) string {
+	return idx.find([]*findParam{
 		{tagName: "meta", attrKey: "property", attrValue: "og:site_name", targetKey: "content"},
 		{tagName: "meta", attrKey: "name", attrValue: "twitter:site", targetKey: "content"},
 	}...)
+}
 
-	if res == "" {
-		res = parsedUrl.Host
+// ダークモード用の指定か
+// 解釈できないメディアクエリはfalseを返す
+func themeColorScheme(media string) (dark bool, ok bool) {
+	media = strings.ToLower(strings.Join(strings.Fields(media), ""))
+	switch {
+	case media == "" || media == "all" || media == "screen":
+		return false, true
+	case strings.Contains(media, "prefers-color-scheme:dark"):
+		return true, true
+	case strings.Contains(media, "prefers-color-scheme:light"):
+		return false, true
 	}
-
-	return res
+	return false, false
 }
 
-func getFavicon(doc *html.Node, parsedUrl url.URL) string {
-	res := analyzeNode(doc, []*findParam{
-		{tagName: "link", attrKey: "rel", attrValue: "shortcut icon", targetKey: "href"},
-		{tagName: "link", attrKey: "rel", attrValue: "icon", targetKey: "href"},
-	}...)
+// theme-colorからライトモードとダークモードの色を取得する
+// ライトモードの指定がなければmsapplication-TileColorを使う
+func getThemeColors(idx *metaIndex) (string, string) {
+	var light, dark string
+	for _, e := range idx.elements[indexKey("meta", "name", "theme-color")] {
+		color, ok := normalizeCssColor(e.attr("content"))
+		if !ok {
+			continue
+		}
+
+		isDark, ok := themeColorScheme(e.attr("media"))
+		if !ok {
+			continue
+		}
+
+		if isDark && dark == "" {
+			dark = color
+		} else if !isDark && light == "" {
+			light = color
+		}
+	}
 
-	if res == "" {
-		res = fmt.Sprintf("https://%s/favicon.ico", parsedUrl.Host)
-	} else if !strings.HasPrefix(res, "https://") {
-		res = fmt.Sprintf("https://%s%s", parsedUrl.Host, res)
+	if light == "" {
+		light, _ = normalizeCssColor(idx.find([]*findParam{
+			{tagName: "meta", attrKey: "name", attrValue: "msapplication-TileColor", targetKey: "content"},
+		}...))
 	}
 
-	return res
+	return light, dark
 }
 
+// SummarizeHtml builds a Summary from an HTML document using the default Summarizer.
 func SummarizeHtml(siteUrl url.URL, body io.Reader, charSet string) (*Summary, error) {
-	doc, err := html.Parse(body)
+	return defaultSummarizer.SummarizeHtml(siteUrl, body, charSet)
+}
+
+// SummarizeHtmlContext is like SummarizeHtml but aborts when ctx is done.
+func SummarizeHtmlContext(ctx context.Context, siteUrl url.URL, body io.Reader, charSet string) (*Summary, error) {
+	return defaultSummarizer.SummarizeHtmlContext(ctx, siteUrl, body, charSet)
+}
+
+// Summarize fetches siteUrl and builds a Summary using the default Summarizer.
+func Summarize(siteUrl string) (*Summary, error) {
+	return defaultSummarizer.Summarize(siteUrl)
+}
+
+// SummarizeContext is like Summarize but aborts when ctx is done.
+func SummarizeContext(ctx context.Context, siteUrl string) (*Summary, error) {
+	return defaultSummarizer.SummarizeContext(ctx, siteUrl)
+}
+
+// SummarizeHtml builds a Summary from an HTML document fetched from siteUrl.
+// charSet is a charset label such as "shift_jis" given by the transport layer.
+// If charSet is empty, the character set is detected from the document.
+func (s *Summarizer) SummarizeHtml(siteUrl url.URL, body io.Reader, charSet string) (*Summary, error) {
+	return s.SummarizeHtmlContext(context.Background(), siteUrl, body, charSet)
+}
+
+// SummarizeHtmlContext is like SummarizeHtml but aborts when ctx is done.
+// The oEmbed request shares the deadline of ctx, bounded by the Summarizer's timeout.
+func (s *Summarizer) SummarizeHtmlContext(ctx context.Context, siteUrl url.URL, body io.Reader, charSet string) (*Summary, error) {
+	return s.summarizeHtml(ctx, siteUrl, &siteUrl, body, charSet, nil)
+}
+
+// docUrlはリダイレクト後の実際に取得したURL
+// embedには取得済みのoEmbedがあれば渡す
+func (s *Summarizer) summarizeHtml(ctx context.Context, siteUrl url.URL, docUrl *url.URL, body io.Reader, charSet string, embed *oembed) (*Summary, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	idx, err := s.parseHtml(ctx, body, charSet)
 	if err != nil {
-		return nil, errors.New("failed to parse html")
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, wrapRequestError(ctxErr)
+		}
+		return nil, err
 	}
 
-	player := getPlayerFromOEmbed(doc)
-	if player == nil {
-		player = &Player{
-			Url:    getPlayerUrl(doc),
-			Width:  getPlayerWidth(doc),
-			Height: getPlayerHeight(doc),
+	// 相対URLはすべてこれに対して解決する
+	base := idx.baseUrl(docUrl)
+
+	if embed == nil && s.oEmbed {
+		// ページで指定されていなければ既知のプロバイダーを使う
+		oembedUrl := resolveUrl(base, getOEmbedUrl(idx))
+		if oembedUrl == "" {
+			oembedUrl = findOEmbedEndpoint(s.oembedProviders, siteUrl.String())
 		}
+		embed = s.fetchOEmbed(ctx, oembedUrl)
 	}
 
-	if player != nil && strings.Contains(player.Url, "youtube.com/embed/") {
-		// プライバシー保護のためwww.youtube-nocookie.comに置き換える
-		player.Url = strings.Replace(player.Url, "youtube.com/embed/", "youtube-nocookie.com/embed/", 1)
+	var manifest *Manifest
+	if s.manifest {
+		manifest = s.fetchManifest(ctx, resolveUrl(base, getManifestUrl(idx)))
 	}
 
-	title := getPageTitle(doc)
-	description := getPageDescription(doc)
-	siteName := getSiteName(doc, siteUrl)
+	if err := ctx.Err(); err != nil {
+		return nil, wrapRequestError(err)
+	}
+
+	var player *Player
+	var requested []string
+	if embed != nil && embed.Html != "" {
+		player, requested = getPlayerFromOEmbed(embed, idx)
+	} else {
+		player = &Player{
+			Url:    getPlayerUrl(idx),
+			Width:  getPlayerWidth(idx),
+			Height: getPlayerHeight(idx),
+		}
+	}
+
+	player.Url = resolveUrl(base, player.Url)
+	playerErr := s.preparePlayer(player, requested)
+
+	title := getPageTitle(idx)
+	description := getPageDescription(idx)
+	siteName := getSiteName(idx)
 
 	// Misskeyが相対パスで返すことがあるので絶対パスに変換する
 	// そもそもここで相対パスを使っていいのか謎だけど
-	thumbnail := getPageImage(doc)
-	if strings.HasPrefix(thumbnail, "/") {
-		thumbnail = siteUrl.Scheme + "://" + siteUrl.Host + thumbnail
-	}
-
-	// shift_jis対策
-	if charSet == "" {
-		if utf8.ValidString(title) {
-			charSet = "utf-8"
-		} else {
-			charsetDetector := chardet.NewTextDetector()
-			charsetResult, err := charsetDetector.DetectAll([]byte(title))
-			if err != nil {
-				// fallback
-				charSet = "utf-8"
-			} else {
-				for _, result := range charsetResult {
-					// fmt.Printf("charset: %s, confidence: %s\n", result.Charset, result.Language)
-					// shift_jis or euc-jpが候補にあるならそれにする
-					if result.Charset == "Shift_JIS" {
-						charSet = "shift_jis"
-						break
-					} else if result.Charset == "EUC-JP" {
-						charSet = "euc-jp"
-						break
-					}
-				}
-				// なければ一番高いのを使う
-				if charSet == "" {
-					charSet = charsetResult[0].Charset
-				}
-			}
+	thumbnail := resolveUrl(base, getPageImage(idx))
+
+	// metaタグがなければJSON-LD、microdataとRDFaの順に使う
+	structuredData := parseJsonLd(idx.jsonLd)
+	structured := summarizeStructuredData(structuredData)
+
+	// microdataとRDFaは本文を辿る必要があるので、それでも足りない場合だけ抽出する
+	// その場合だけStructuredDataにも含める
+	missing := (title == "" && structured.title == "") || (description == "" && structured.description == "") || (thumbnail == "" && structured.thumbnail == "")
+	if missing && idx.doc != nil {
+		microdata := append(extractMicrodata(idx.doc), extractRdfa(idx.doc)...)
+		structured.fill(summarizeStructuredData(microdata))
+		structuredData = append(structuredData, microdata...)
+	}
+	if title == "" {
+		title = structured.title
+	}
+	if description == "" {
+		description = structured.description
+	}
+	if thumbnail == "" {
+		thumbnail = resolveUrl(base, structured.thumbnail)
+	}
+	if siteName == "" {
+		siteName = structured.siteName
+	}
+
+	// それでも説明がなければ本文から作る
+	if description == "" && s.contentExtraction && idx.doc != nil {
+		description = extractExcerpt(idx.doc)
+	}
+
+	// OGPが足りないサイトはoEmbedの情報で補う
+	if embed != nil {
+		if title == "" {
+			title = embed.Title
+		}
+		if thumbnail == "" {
+			thumbnail = resolveUrl(base, embed.thumbnail())
+		}
+		if siteName == "" {
+			siteName = embed.ProviderName
 		}
 	}
 
-	// そのうち他の文字コードにも対応する？
-	if strings.ToLower(charSet) == "shift_jis" {
-		title = convertShiftJisToUtf8(title)
-		description = convertShiftJisToUtf8(description)
-		siteName = convertShiftJisToUtf8(siteName)
-	} else if strings.ToLower(charSet) == "euc-jp" {
-		title = convertEucJpToUtf8(title)
-		description = convertEucJpToUtf8(description)
-		siteName = convertEucJpToUtf8(siteName)
+	if siteName == "" && manifest != nil {
+		siteName = manifest.siteName()
+	}
+	if siteName == "" {
+		siteName = siteUrl.Host
+	}
+
+	icon := s.getFavicon(ctx, idx, base, docUrl, manifest)
+
+	themeColor, themeColorDark := getThemeColors(idx)
+	if themeColor == "" && manifest != nil {
+		themeColor, _ = normalizeCssColor(manifest.ThemeColor)
 	}
 
 	return &Summary{
-		Url:         siteUrl.String(),
-		Title:       title,
-		Description: description,
-		Thumbnail:   thumbnail,
-		SiteName:    siteName,
-		Icon:        getFavicon(doc, siteUrl),
-		ActivityPub: getActivityPubLink(doc),
-		Sensitive:   isSensitive(doc, siteUrl),
-		Player:      *player,
+		Url:            siteUrl.String(),
+		Title:          title,
+		Description:    description,
+		Thumbnail:      thumbnail,
+		SiteName:       siteName,
+		Icon:           icon,
+		ActivityPub:    resolveUrl(base, getActivityPubLink(idx)),
+		Sensitive:      isSensitive(idx, siteUrl),
+		Player:         *player,
+		ThemeColor:     themeColor,
+		ThemeColorDark: themeColorDark,
+		Article:        getArticle(idx, structuredData),
+		StructuredData: structuredData,
+		Manifest:       manifest,
+		PlayerError:    playerErr,
 	}, nil
 }
 
-func Summarize(siteUrl string) (*Summary, error) {
+// Summarize fetches siteUrl and builds a Summary from it.
+func (s *Summarizer) Summarize(siteUrl string) (*Summary, error) {
+	return s.SummarizeContext(context.Background(), siteUrl)
+}
+
+// SummarizeContext is like Summarize but aborts when ctx is done.
+// The page and oEmbed requests share a single deadline bounded by the Summarizer's timeout.
+// Concurrent calls for the same URL share one fetch; canceling ctx only abandons the wait
+// unless no other caller is waiting.
+func (s *Summarizer) SummarizeContext(ctx context.Context, siteUrl string) (*Summary, error) {
 	parsedUrl, err := url.Parse(siteUrl)
 	if err != nil {
-		return nil, errors.New("failed to parse url")
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	} else if (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidURL, siteUrl)
 	}
 
-	req, newReqErr := http.NewRequest("GET", siteUrl, nil)
+	key := cacheKey(parsedUrl, languageFromContext(ctx))
+	if s.cache != nil {
+		if summary, ok := s.cache.Get(key); ok {
+			return summary.clone(), nil
+		}
+	}
+
+	// 同じURLを同時に要求された場合は1回の取得を共有する
+	return s.flight.do(ctx, key, func(ctx context.Context) (*Summary, error) {
+		ctx, cancel := s.withTimeout(ctx)
+		defer cancel()
+
+		summary, ttl, err := s.summarize(ctx, parsedUrl)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.cache != nil && ttl > 0 {
+			s.cache.Set(key, summary.clone(), time.Now().Add(ttl))
+		}
+
+		return summary, nil
+	})
+}
+
+// ページを取得してSummaryとキャッシュしてよい期間を返す
+func (s *Summarizer) summarize(ctx context.Context, parsedUrl *url.URL) (*Summary, time.Duration, error) {
+	// サイト固有のプラグインがあればそちらを使う
+	if plugin := s.findPlugin(parsedUrl); plugin != nil {
+		summary, err := plugin.Summarize(ctx, parsedUrl)
+		if err != nil {
+			return nil, 0, err
+		} else if summary != nil {
+			return summary, s.cacheTTL(http.Header{}, time.Now()), nil
+		}
+	}
+
+	// oEmbedだけで十分な情報が得られるならページは取得しない
+	var embed *oembed
+	if s.oEmbed && s.oEmbedShortcut {
+		if endpoint := findOEmbedEndpoint(s.oembedProviders, parsedUrl.String()); endpoint != "" {
+			embed = s.fetchOEmbed(ctx, endpoint)
+			if embed != nil && embed.complete() {
+				return s.summaryFromOEmbed(ctx, parsedUrl, embed), s.cacheTTL(embed.cacheHeader(), time.Now()), nil
+			}
+		}
+	}
+
+	req, newReqErr := http.NewRequestWithContext(ctx, "GET", parsedUrl.String(), nil)
 	if newReqErr != nil {
-		return nil, errors.New("failed to create request")
+		return nil, 0, fmt.Errorf("%w: %w", ErrInvalidURL, newReqErr)
 	}
 
 	// :)
@@ -310,40 +470,40 @@ func Summarize(siteUrl string) (*Summary, error) {
 	} else if parsedHost == "www.sankei.com" || parsedHost == "abema.tv" {
 		req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.1 Safari/605.1.15")
 	} else {
-		req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; SummerGo/0.1;)")
+		req.Header.Set("User-Agent", s.userAgent)
 	}
 
-	requester := archer.SecureRequest{
-		Request:     req,
-		TimeoutSecs: 10,
-		MaxSize:     1024 * 1024 * 10,
+	if lang := languageFromContext(ctx); lang != "" {
+		req.Header.Set("Accept-Language", lang)
 	}
 
-	resp, respErr := requester.Send()
+	resp, respErr := s.send(req)
 
 	if respErr != nil {
-		return nil, respErr
+		return nil, 0, wrapRequestError(respErr)
 	} else if resp.StatusCode != 200 {
-		return nil, errors.New("non-200 status code: " + resp.Status)
+		_ = resp.Body.Close()
+		return nil, 0, &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
 	}
 
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			panic(err)
-		}
-	}(resp.Body)
+	defer resp.Body.Close()
 
-	// サーバーからのレスポンスでcharsetを明示しているならそれを使って高速化する
-	var knownCharset string
+	// HTML以外は弾く。charsetを明示しているならそれを優先する
 	contentType := resp.Header.Get("Content-Type")
-	if strings.Contains(strings.ToLower(contentType), "utf-8") {
-		knownCharset = "utf-8"
-	} else if strings.Contains(strings.ToLower(contentType), "shift_jis") {
-		knownCharset = "shift_jis"
-	} else if strings.Contains(strings.ToLower(contentType), "euc-jp") {
-		knownCharset = "euc-jp"
+	if !isHtmlContentType(contentType) {
+		return nil, 0, fmt.Errorf("%w: %s", ErrNotHTML, contentType)
+	}
+
+	// リダイレクトされた場合は最終的なURLを基準にする
+	docUrl := parsedUrl
+	if resp.Request != nil && resp.Request.URL != nil {
+		docUrl = resp.Request.URL
+	}
+
+	summary, err := s.summarizeHtml(ctx, *parsedUrl, docUrl, resp.Body, charsetFromContentType(contentType), embed)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return SummarizeHtml(*parsedUrl, resp.Body, knownCharset)
+	return summary, s.cacheTTL(resp.Header, time.Now()), nil
 }
@@ -0,0 +1,86 @@
+package summergo
+
+import (
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/nexryai/archer"
+)
+
+// netパッケージで判定できないもの (https://ipinfo.io/bogon)
+var privateNetworks = func() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8",
+		"100.64.0.0/10",
+		"64:ff9b::/96",
+		"64:ff9b:1::/48",
+		"2001:10::/28",
+		"2001:db8::/32",
+		"::/96",
+	} {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// 接続してはいけないアドレスか
+func isPrivateAddress(ip net.IP) bool {
+	if ip == nil {
+		return true
+	}
+	// IPv4射影アドレスはIPv4として判定する
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsMulticast() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() || !ip.IsGlobalUnicast() {
+		return true
+	}
+
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// 名前解決した後の実際に接続するアドレスを検証する
+// 接続ごとに検証するので、同時に送ったリクエストが互いの接続先を使うこともない
+func checkDialAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if isPrivateAddress(net.ParseIP(host)) {
+		return archer.ErrPrivateAddressDetected
+	}
+	return nil
+}
+
+// newSafeTransport creates the transport used when no transport is given with WithTransport.
+// It refuses to connect to private addresses, and ignores proxy settings so that the check
+// applies to the origin itself.
+func newSafeTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   checkDialAddress,
+	}
+
+	return &http.Transport{
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
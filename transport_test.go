@@ -0,0 +1,46 @@
+package summergo
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/nexryai/archer"
+)
+
+func TestIsPrivateAddress(t *testing.T) {
+	for _, address := range []string{"127.0.0.1", "10.0.0.1", "192.168.1.1", "100.64.0.1", "169.254.169.254", "0.0.0.0", "::1", "fe80::1", "::ffff:127.0.0.1", "64:ff9b::7f00:1", "2001:db8::1"} {
+		if !isPrivateAddress(net.ParseIP(address)) {
+			t.Errorf("%s should be private", address)
+		}
+	}
+
+	for _, address := range []string{"8.8.8.8", "1.1.1.1", "2606:4700:4700::1111"} {
+		if isPrivateAddress(net.ParseIP(address)) {
+			t.Errorf("%s should be public", address)
+		}
+	}
+}
+
+func TestSafeTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	// 名前解決した後のアドレスも検証する
+	serverUrl, _ := url.Parse(server.URL)
+	for _, host := range []string{serverUrl.Host, net.JoinHostPort("localhost", serverUrl.Port())} {
+		req, _ := http.NewRequest("GET", "http://"+host+"/", nil)
+		resp, err := newSafeTransport().RoundTrip(req)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		if !errors.Is(err, archer.ErrPrivateAddressDetected) {
+			t.Errorf("%s: Expected: %v, Got: %v", host, archer.ErrPrivateAddressDetected, err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package summergo
+
+import (
+	"net/url"
+	"strings"
+)
+
+// baseUrl returns the URL that relative links in the document are resolved against.
+// docUrl is the URL the document was finally fetched from; the first <base href> overrides it.
+func (idx *metaIndex) baseUrl(docUrl *url.URL) *url.URL {
+	for _, e := range idx.bases {
+		href := strings.TrimSpace(e.attr("href"))
+		if href == "" {
+			continue
+		}
+
+		// 解決できないbaseは無視する
+		base, err := docUrl.Parse(href)
+		if err != nil || (base.Scheme != "http" && base.Scheme != "https") {
+			return docUrl
+		}
+		return base
+	}
+
+	return docUrl
+}
+
+// refをbaseに対して解決して絶対URLにする
+// 空や解決できないものは空文字列を返す
+func resolveUrl(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+
+	resolved, err := base.Parse(ref)
+	if err != nil {
+		return ""
+	}
+
+	return resolved.String()
+}
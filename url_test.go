@@ -0,0 +1,128 @@
+package summergo
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestResolveUrl(t *testing.T) {
+	base, _ := url.Parse("http://example.com/blog/post/1")
+
+	tests := map[string]string{
+		"/image.png":                  "http://example.com/image.png",
+		"icon.png":                    "http://example.com/blog/post/icon.png",
+		"../x.png":                    "http://example.com/blog/x.png",
+		"//cdn.example.net/a.png":     "http://cdn.example.net/a.png",
+		"https://other.example.org/b": "https://other.example.org/b",
+		"  /trimmed.png\n":            "http://example.com/trimmed.png",
+		"?page=2":                     "http://example.com/blog/post/1?page=2",
+		"":                            "",
+		"http://[invalid":             "",
+		"data:image/png;base64,AAAA":  "data:image/png;base64,AAAA",
+	}
+
+	for ref, expected := range tests {
+		if result := resolveUrl(base, ref); result != expected {
+			t.Errorf("%q: Expected: %s, Got: %s", ref, expected, result)
+		}
+	}
+}
+
+func TestBaseUrl(t *testing.T) {
+	docUrl, _ := url.Parse("https://example.com/a/b")
+
+	idx := newEmptyMetaIndex()
+	if result := idx.baseUrl(docUrl).String(); result != docUrl.String() {
+		t.Errorf("Expected: %s, Got: %s", docUrl, result)
+	}
+
+	// 最初のhrefを持つbaseだけが使われる
+	idx.add("base", nil, "")
+	idx.add("base", []html.Attribute{{Key: "href", Val: "/static/"}}, "")
+	idx.add("base", []html.Attribute{{Key: "href", Val: "https://other.example.com/"}}, "")
+	if result := idx.baseUrl(docUrl).String(); result != "https://example.com/static/" {
+		t.Errorf("Expected: %s, Got: %s", "https://example.com/static/", result)
+	}
+
+	// 不正なbaseは無視する
+	idx = newEmptyMetaIndex()
+	idx.add("base", []html.Attribute{{Key: "href", Val: "javascript:void(0)"}}, "")
+	if result := idx.baseUrl(docUrl).String(); result != docUrl.String() {
+		t.Errorf("Expected: %s, Got: %s", docUrl, result)
+	}
+}
+
+func TestSummarizeHtmlResolvesUrls(t *testing.T) {
+	siteUrl, _ := url.Parse("http://example.com/articles/1")
+	s := NewSummarizer(WithOEmbed(false))
+
+	page := `<html><head>
+		<base href="/assets/">
+		<title>Title</title>
+		<meta name="description" content="Description">
+		<meta property="og:image" content="images/thumb.png">
+		<link rel="icon" href="../favicon.png">
+		<link type="application/activity+json" href="//ap.example.com/notes/1">
+	</head><body></body></html>`
+
+	summary, err := s.SummarizeHtml(*siteUrl, strings.NewReader(page), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Thumbnail != "http://example.com/assets/images/thumb.png" {
+		t.Errorf("Expected: %s, Got: %s", "http://example.com/assets/images/thumb.png", summary.Thumbnail)
+	}
+	if summary.Icon != "http://example.com/favicon.png" {
+		t.Errorf("Expected: %s, Got: %s", "http://example.com/favicon.png", summary.Icon)
+	}
+	if summary.ActivityPub != "http://ap.example.com/notes/1" {
+		t.Errorf("Expected: %s, Got: %s", "http://ap.example.com/notes/1", summary.ActivityPub)
+	}
+
+	// faviconの指定がなければページのオリジンの/favicon.icoを使う
+	summary, err = s.SummarizeHtml(*siteUrl, strings.NewReader(`<title>Title</title>`), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Icon != "http://example.com/favicon.ico" {
+		t.Errorf("Expected: %s, Got: %s", "http://example.com/favicon.ico", summary.Icon)
+	}
+}
+
+func TestSummarizeResolvesAgainstRedirectedUrl(t *testing.T) {
+	s := NewSummarizer(
+		WithOEmbed(false),
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Host == "example.com" {
+				resp := newTestResponse(req, 301, "", "")
+				resp.Header.Set("Location", "https://www.example.com/new/page")
+				return resp, nil
+			}
+
+			return newTestResponse(req, 200, "text/html", `<title>Title</title><meta property="og:image" content="thumb.png"><meta property="og:video" content="player">`), nil
+		})),
+	)
+
+	summary, err := s.Summarize("https://example.com/old")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Url != "https://example.com/old" {
+		t.Errorf("Expected: %s, Got: %s", "https://example.com/old", summary.Url)
+	}
+	if summary.Thumbnail != "https://www.example.com/new/thumb.png" {
+		t.Errorf("Expected: %s, Got: %s", "https://www.example.com/new/thumb.png", summary.Thumbnail)
+	}
+	if summary.Player.Url != "https://www.example.com/new/player" {
+		t.Errorf("Expected: %s, Got: %s", "https://www.example.com/new/player", summary.Player.Url)
+	}
+	if summary.Icon != "https://www.example.com/favicon.ico" {
+		t.Errorf("Expected: %s, Got: %s", "https://www.example.com/favicon.ico", summary.Icon)
+	}
+}